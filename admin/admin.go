@@ -1,26 +1,68 @@
 package admin
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/rs/xid"
 
+	"github.com/tatsujin1/janus-go"
 	"github.com/tatsujin1/janus-go/plugins"
 )
 
+// Log levels of the server, for SetLogLevel.
+const (
+	LogNone = iota
+	LogFatal
+	LogError
+	LogWarn
+	LogInfo
+	LogVerbose
+	LogHuge
+	LogDebug
+)
+
 type AdminAPI interface {
-	AddToken(token string, plugins []string) (interface{}, error)
-	AllowToken(token string, plugins []string) (interface{}, error)
-	DisallowToken(token string, plugins []string) (interface{}, error)
-	RemoveToken(token string) (interface{}, error)
-	ListTokens() (interface{}, error)
+	Info() (*janus.InfoMsg, error)
+	Ping() error
+	GetStatus() (*ServerStatus, error)
+
+	SetSessionTimeout(timeout int) (int, error)
+	SetLogLevel(level int) (int, error)
+	SetLogTimestamps(timestamps bool) (bool, error)
+	SetLogColors(colors bool) (bool, error)
+	SetLockingDebug(debug bool) (bool, error)
+	SetRefcountDebug(debug bool) (bool, error)
+	SetLibniceDebug(debug bool) (bool, error)
+	SetMinNackQueue(minNackQueue int) (int, error)
+	SetNoMediaTimer(noMediaTimer int) (int, error)
+	SetSlowlinkThreshold(threshold int) (int, error)
+	AcceptNewSessions(accept bool) (bool, error)
+
+	AddToken(token string, plugins []string) ([]string, error)
+	AllowToken(token string, plugins []string) ([]string, error)
+	DisallowToken(token string, plugins []string) ([]string, error)
+	RemoveToken(token string) error
+	ListTokens() ([]*StoredToken, error)
 
-	ListSessions() (interface{}, error)
+	ListSessions() ([]uint64, error)
 	MessagePlugin(request plugins.PluginRequest) (interface{}, error)
+	DestroySession(sessionID uint64) error
 
-	ListHandles(sessionID uint64) (interface{}, error)
-	HandleInfo(sessionID, handleID uint64) (interface{}, error)
+	ListHandles(sessionID uint64) ([]uint64, error)
+	HandleInfo(sessionID, handleID uint64) (*HandleInfo, error)
+	DetachHandle(sessionID, handleID uint64) error
+	HangupWebRTC(sessionID, handleID uint64) error
+	StartPcap(sessionID, handleID uint64, folder, filename string, truncate int) error
+	StopPcap(sessionID, handleID uint64) error
+	StartText2pcap(sessionID, handleID uint64, folder, filename string, truncate int) error
+	StopText2pcap(sessionID, handleID uint64) error
+	MessageHandle(sessionID, handleID uint64, request plugins.PluginRequest) (interface{}, error)
+
+	// Request sends any request and returns the response envelope, e.g. a
+	// *ListSessionsResponse for a list_sessions request.
+	Request(request APIRequest) (interface{}, error)
 
 	Close() error
 }
@@ -36,6 +78,12 @@ func NewAdminAPI(url, secret string) (*DefaultAdminAPI, error) {
 
 	if strings.HasPrefix(url, "http") {
 		api.transport = NewHttpTransport(url)
+	} else if strings.HasPrefix(url, "unix") {
+		transport, err := NewUnixTransport(url)
+		if err != nil {
+			return nil, err
+		}
+		api.transport = transport
 	} else {
 		return nil, fmt.Errorf("unsupported transport for %s", url)
 	}
@@ -43,47 +91,385 @@ func NewAdminAPI(url, secret string) (*DefaultAdminAPI, error) {
 	return api, nil
 }
 
-func (api *DefaultAdminAPI) AddToken(token string, plugins []string) (interface{}, error) {
-	return api.transport.Request(api.makeTokenRequest("add_token", token, plugins))
+// Info returns the name, version and plugins of the server.
+// On success, error will be nil.
+func (api *DefaultAdminAPI) Info() (*janus.InfoMsg, error) {
+	resp, err := api.Request(api.MakeBaseRequest("info"))
+	if err != nil {
+		return nil, err
+	}
+	info, ok := resp.(*InfoResponse)
+	if !ok {
+		return nil, unexpectedResponse("info", resp)
+	}
+	return &info.InfoMsg, nil
+}
+
+func (api *DefaultAdminAPI) Ping() error {
+	resp, err := api.Request(api.MakeBaseRequest("ping"))
+	if err != nil {
+		return err
+	}
+	if _, ok := resp.(*PongResponse); !ok {
+		return unexpectedResponse("ping", resp)
+	}
+	return nil
+}
+
+// GetStatus returns the runtime settings of the server.
+// On success, error will be nil.
+func (api *DefaultAdminAPI) GetStatus() (*ServerStatus, error) {
+	resp, err := api.Request(api.MakeBaseRequest("get_status"))
+	if err != nil {
+		return nil, err
+	}
+	status, ok := resp.(*StatusResponse)
+	if !ok {
+		return nil, unexpectedResponse("get_status", resp)
+	}
+	return status.Status, nil
+}
+
+// SetSessionTimeout sets the timeout in seconds after which sessions without
+// activity are destroyed, 0 to disable it.
+// On success, the new timeout will be returned and error will be nil.
+func (api *DefaultAdminAPI) SetSessionTimeout(timeout int) (int, error) {
+	resp, err := api.Request(api.MakeSettingRequest("set_session_timeout", "timeout", timeout))
+	if err != nil {
+		return 0, err
+	}
+	setting, ok := resp.(*SessionTimeoutResponse)
+	if !ok {
+		return 0, unexpectedResponse("set_session_timeout", resp)
+	}
+	return setting.Timeout, nil
+}
+
+// SetLogLevel sets the log level of the server, from LogNone to LogDebug.
+// On success, the new level will be returned and error will be nil.
+func (api *DefaultAdminAPI) SetLogLevel(level int) (int, error) {
+	resp, err := api.Request(api.MakeSettingRequest("set_log_level", "level", level))
+	if err != nil {
+		return 0, err
+	}
+	setting, ok := resp.(*LogLevelResponse)
+	if !ok {
+		return 0, unexpectedResponse("set_log_level", resp)
+	}
+	return setting.Level, nil
+}
+
+func (api *DefaultAdminAPI) SetLogTimestamps(timestamps bool) (bool, error) {
+	resp, err := api.Request(api.MakeSettingRequest("set_log_timestamps", "timestamps", timestamps))
+	if err != nil {
+		return false, err
+	}
+	setting, ok := resp.(*LogTimestampsResponse)
+	if !ok {
+		return false, unexpectedResponse("set_log_timestamps", resp)
+	}
+	return setting.LogTimestamps, nil
+}
+
+func (api *DefaultAdminAPI) SetLogColors(colors bool) (bool, error) {
+	resp, err := api.Request(api.MakeSettingRequest("set_log_colors", "colors", colors))
+	if err != nil {
+		return false, err
+	}
+	setting, ok := resp.(*LogColorsResponse)
+	if !ok {
+		return false, unexpectedResponse("set_log_colors", resp)
+	}
+	return setting.LogColors, nil
 }
 
-func (api *DefaultAdminAPI) AllowToken(token string, plugins []string) (interface{}, error) {
-	return api.transport.Request(api.makeTokenRequest("allow_token", token, plugins))
+func (api *DefaultAdminAPI) SetLockingDebug(debug bool) (bool, error) {
+	resp, err := api.Request(api.MakeSettingRequest("set_locking_debug", "debug", debug))
+	if err != nil {
+		return false, err
+	}
+	setting, ok := resp.(*LockingDebugResponse)
+	if !ok {
+		return false, unexpectedResponse("set_locking_debug", resp)
+	}
+	return setting.LockingDebug, nil
 }
 
-func (api *DefaultAdminAPI) DisallowToken(token string, plugins []string) (interface{}, error) {
-	return api.transport.Request(api.makeTokenRequest("disallow_token", token, plugins))
+func (api *DefaultAdminAPI) SetRefcountDebug(debug bool) (bool, error) {
+	resp, err := api.Request(api.MakeSettingRequest("set_refcount_debug", "debug", debug))
+	if err != nil {
+		return false, err
+	}
+	setting, ok := resp.(*RefcountDebugResponse)
+	if !ok {
+		return false, unexpectedResponse("set_refcount_debug", resp)
+	}
+	return setting.RefcountDebug, nil
 }
 
-func (api *DefaultAdminAPI) RemoveToken(token string) (interface{}, error) {
-	return api.transport.Request(api.makeTokenRequest("remove_token", token, nil))
+func (api *DefaultAdminAPI) SetLibniceDebug(debug bool) (bool, error) {
+	resp, err := api.Request(api.MakeSettingRequest("set_libnice_debug", "debug", debug))
+	if err != nil {
+		return false, err
+	}
+	setting, ok := resp.(*LibniceDebugResponse)
+	if !ok {
+		return false, unexpectedResponse("set_libnice_debug", resp)
+	}
+	return setting.LibniceDebug, nil
 }
 
-func (api *DefaultAdminAPI) ListTokens() (interface{}, error) {
-	return api.transport.Request(api.makeBaseRequest("list_tokens"))
+// SetMinNackQueue sets the minimum size in milliseconds of the queue of
+// packets kept for retransmissions.
+// On success, the new size will be returned and error will be nil.
+func (api *DefaultAdminAPI) SetMinNackQueue(minNackQueue int) (int, error) {
+	resp, err := api.Request(api.MakeSettingRequest("set_min_nack_queue", "min_nack_queue", minNackQueue))
+	if err != nil {
+		return 0, err
+	}
+	setting, ok := resp.(*MinNackQueueResponse)
+	if !ok {
+		return 0, unexpectedResponse("set_min_nack_queue", resp)
+	}
+	return setting.MinNackQueue, nil
 }
 
-func (api *DefaultAdminAPI) ListSessions() (interface{}, error) {
-	return api.transport.Request(api.makeBaseRequest("list_sessions"))
+// SetNoMediaTimer sets the time in seconds without media after which a
+// PeerConnection is reported as down.
+// On success, the new time will be returned and error will be nil.
+func (api *DefaultAdminAPI) SetNoMediaTimer(noMediaTimer int) (int, error) {
+	resp, err := api.Request(api.MakeSettingRequest("set_no_media_timer", "no_media_timer", noMediaTimer))
+	if err != nil {
+		return 0, err
+	}
+	setting, ok := resp.(*NoMediaTimerResponse)
+	if !ok {
+		return 0, unexpectedResponse("set_no_media_timer", resp)
+	}
+	return setting.NoMediaTimer, nil
 }
 
+// SetSlowlinkThreshold sets the number of lost packets per second above
+// which a slow link is reported, 0 to disable the reports.
+// On success, the new threshold will be returned and error will be nil.
+func (api *DefaultAdminAPI) SetSlowlinkThreshold(threshold int) (int, error) {
+	resp, err := api.Request(api.MakeSettingRequest("set_slowlink_threshold", "slowlink_threshold", threshold))
+	if err != nil {
+		return 0, err
+	}
+	setting, ok := resp.(*SlowlinkThresholdResponse)
+	if !ok {
+		return 0, unexpectedResponse("set_slowlink_threshold", resp)
+	}
+	return setting.SlowlinkThreshold, nil
+}
+
+// AcceptNewSessions makes the server accept or refuse the creation of new
+// sessions, e.g. to drain it before a restart.
+// On success, whether new sessions are accepted will be returned and error
+// will be nil.
+func (api *DefaultAdminAPI) AcceptNewSessions(accept bool) (bool, error) {
+	resp, err := api.Request(api.MakeSettingRequest("accept_new_sessions", "accept", accept))
+	if err != nil {
+		return false, err
+	}
+	setting, ok := resp.(*AcceptNewSessionsResponse)
+	if !ok {
+		return false, unexpectedResponse("accept_new_sessions", resp)
+	}
+	return setting.Accept, nil
+}
+
+// AddToken adds a token allowed to use plugins, all of them if empty.
+// On success, the plugins the token is allowed to use will be returned and
+// error will be nil.
+func (api *DefaultAdminAPI) AddToken(token string, plugins []string) ([]string, error) {
+	return api.tokenRequest(api.MakeTokenRequest("add_token", token, plugins))
+}
+
+func (api *DefaultAdminAPI) AllowToken(token string, plugins []string) ([]string, error) {
+	return api.tokenRequest(api.MakeTokenRequest("allow_token", token, plugins))
+}
+
+func (api *DefaultAdminAPI) DisallowToken(token string, plugins []string) ([]string, error) {
+	return api.tokenRequest(api.MakeTokenRequest("disallow_token", token, plugins))
+}
+
+func (api *DefaultAdminAPI) RemoveToken(token string) error {
+	_, err := api.Request(api.MakeTokenRequest("remove_token", token, nil))
+	return err
+}
+
+func (api *DefaultAdminAPI) ListTokens() ([]*StoredToken, error) {
+	resp, err := api.Request(api.MakeBaseRequest("list_tokens"))
+	if err != nil {
+		return nil, err
+	}
+	tokens, ok := resp.(*ListTokensResponse)
+	if !ok {
+		return nil, unexpectedResponse("list_tokens", resp)
+	}
+	return tokens.Data["tokens"], nil
+}
+
+func (api *DefaultAdminAPI) ListSessions() ([]uint64, error) {
+	resp, err := api.Request(api.MakeBaseRequest("list_sessions"))
+	if err != nil {
+		return nil, err
+	}
+	sessions, ok := resp.(*ListSessionsResponse)
+	if !ok {
+		return nil, unexpectedResponse("list_sessions", resp)
+	}
+	return sessions.Sessions, nil
+}
+
+// MessagePlugin sends a request to a plugin.
+// On success, the response of the plugin will be returned, decoded to the
+// type registered for the request in plugins.ResponseTypeMap if any, and
+// error will be nil.
 func (api *DefaultAdminAPI) MessagePlugin(request plugins.PluginRequest) (interface{}, error) {
-	return api.transport.Request(api.makeMessagePluginRequest(request))
+	return api.Request(api.MakeMessagePluginRequest(request))
+}
+
+func (api *DefaultAdminAPI) ListHandles(sessionID uint64) ([]uint64, error) {
+	resp, err := api.Request(api.MakeSessionRequest("list_handles", sessionID))
+	if err != nil {
+		return nil, err
+	}
+	handles, ok := resp.(*ListHandlesResponse)
+	if !ok {
+		return nil, unexpectedResponse("list_handles", resp)
+	}
+	return handles.Handles, nil
+}
+
+func (api *DefaultAdminAPI) HandleInfo(sessionID, handleID uint64) (*HandleInfo, error) {
+	resp, err := api.Request(api.MakeHandleRequest("handle_info", sessionID, handleID))
+	if err != nil {
+		return nil, err
+	}
+	info, ok := resp.(*HandleInfoResponse)
+	if !ok {
+		return nil, unexpectedResponse("handle_info", resp)
+	}
+	return info.Info, nil
+}
+
+// DestroySession destroys a session and detaches its handles.
+// On success, error will be nil.
+func (api *DefaultAdminAPI) DestroySession(sessionID uint64) error {
+	resp, err := api.Request(api.MakeSessionRequest("destroy_session", sessionID))
+	if err != nil {
+		return err
+	}
+	if _, ok := resp.(*DestroySessionResponse); !ok {
+		return unexpectedResponse("destroy_session", resp)
+	}
+	return nil
+}
+
+// DetachHandle detaches a handle from its plugin.
+// On success, error will be nil.
+func (api *DefaultAdminAPI) DetachHandle(sessionID, handleID uint64) error {
+	resp, err := api.Request(api.MakeHandleRequest("detach_handle", sessionID, handleID))
+	if err != nil {
+		return err
+	}
+	if _, ok := resp.(*DetachHandleResponse); !ok {
+		return unexpectedResponse("detach_handle", resp)
+	}
+	return nil
+}
+
+// HangupWebRTC closes the PeerConnection of a handle, which stays attached.
+// On success, error will be nil.
+func (api *DefaultAdminAPI) HangupWebRTC(sessionID, handleID uint64) error {
+	resp, err := api.Request(api.MakeHandleRequest("hangup_webrtc", sessionID, handleID))
+	if err != nil {
+		return err
+	}
+	if _, ok := resp.(*HangupWebRTCResponse); !ok {
+		return unexpectedResponse("hangup_webrtc", resp)
+	}
+	return nil
+}
+
+// StartPcap starts capturing the unencrypted traffic of a handle to a pcap
+// file named filename in folder, both picked by the server if empty.
+// Packets are truncated to truncate bytes if not 0.
+// On success, error will be nil.
+func (api *DefaultAdminAPI) StartPcap(sessionID, handleID uint64, folder, filename string, truncate int) error {
+	return api.capture(api.MakePcapRequest("start_pcap", sessionID, handleID, folder, filename, truncate))
 }
 
-func (api *DefaultAdminAPI) ListHandles(sessionID uint64) (interface{}, error) {
-	return api.transport.Request(api.makeSessionRequest("list_handles", sessionID))
+func (api *DefaultAdminAPI) StopPcap(sessionID, handleID uint64) error {
+	return api.capture(api.MakeHandleRequest("stop_pcap", sessionID, handleID))
 }
 
-func (api *DefaultAdminAPI) HandleInfo(sessionID, handleID uint64) (interface{}, error) {
-	return api.transport.Request(api.makeHandleRequest("handle_info", sessionID, handleID))
+// StartText2pcap is like StartPcap, but captures to a text file which can
+// be converted to pcap with text2pcap.
+func (api *DefaultAdminAPI) StartText2pcap(sessionID, handleID uint64, folder, filename string, truncate int) error {
+	return api.capture(api.MakePcapRequest("start_text2pcap", sessionID, handleID, folder, filename, truncate))
+}
+
+func (api *DefaultAdminAPI) StopText2pcap(sessionID, handleID uint64) error {
+	return api.capture(api.MakeHandleRequest("stop_text2pcap", sessionID, handleID))
+}
+
+// MessageHandle sends a request to the plugin a handle is attached to, on
+// behalf of the handle.
+// On success, the response of the plugin will be returned, decoded like the
+// ones of MessagePlugin, and error will be nil.
+func (api *DefaultAdminAPI) MessageHandle(sessionID, handleID uint64, request plugins.PluginRequest) (interface{}, error) {
+	return api.Request(api.MakeMessageHandleRequest(sessionID, handleID, request))
+}
+
+func (api *DefaultAdminAPI) Request(request APIRequest) (interface{}, error) {
+	return api.transport.Request(request)
+}
+
+// RequestContext is like Request, but gives up waiting for the response
+// when ctx is done, if the transport is a ContextTransport.
+func (api *DefaultAdminAPI) RequestContext(ctx context.Context, request APIRequest) (interface{}, error) {
+	if transport, ok := api.transport.(ContextTransport); ok {
+		return transport.RequestContext(ctx, request)
+	}
+	return api.transport.Request(request)
+}
+
+func (api *DefaultAdminAPI) tokenRequest(request *TokenRequest) ([]string, error) {
+	resp, err := api.Request(request)
+	if err != nil {
+		return nil, err
+	}
+	token, ok := resp.(*TokenResponse)
+	if !ok {
+		return nil, unexpectedResponse(request.Action, resp)
+	}
+	return token.Data.Plugins, nil
+}
+
+func (api *DefaultAdminAPI) capture(request APIRequest) error {
+	resp, err := api.Request(request)
+	if err != nil {
+		return err
+	}
+	if _, ok := resp.(*CaptureResponse); !ok {
+		return unexpectedResponse(request.ActionName(), resp)
+	}
+	return nil
+}
+
+func unexpectedResponse(action string, resp interface{}) error {
+	return fmt.Errorf("unexpected response to %s: %v", action, resp)
 }
 
 func (api *DefaultAdminAPI) Close() error {
 	return api.transport.Close()
 }
 
-func (api *DefaultAdminAPI) makeBaseRequest(action string) *BaseRequest {
+func (api *DefaultAdminAPI) MakeBaseRequest(action string) *BaseRequest {
 	return &BaseRequest{
 		Action:      action,
 		Transaction: xid.New().String(),
@@ -91,31 +477,55 @@ func (api *DefaultAdminAPI) makeBaseRequest(action string) *BaseRequest {
 	}
 }
 
-func (api *DefaultAdminAPI) makeTokenRequest(action, token string, plugins []string) *TokenRequest {
+func (api *DefaultAdminAPI) MakeTokenRequest(action, token string, plugins []string) *TokenRequest {
 	return &TokenRequest{
-		BaseRequest: *api.makeBaseRequest(action),
+		BaseRequest: *api.MakeBaseRequest(action),
 		Token:       token,
 		Plugins:     plugins,
 	}
 }
 
-func (api *DefaultAdminAPI) makeMessagePluginRequest(request plugins.PluginRequest) *MessagePluginRequest {
+func (api *DefaultAdminAPI) MakeSettingRequest(action, name string, value interface{}) *SettingRequest {
+	return &SettingRequest{
+		BaseRequest: *api.MakeBaseRequest(action),
+		Name:        name,
+		Value:       value,
+	}
+}
+
+func (api *DefaultAdminAPI) MakeMessagePluginRequest(request plugins.PluginRequest) *MessagePluginRequest {
 	return &MessagePluginRequest{
-		BaseRequest: *api.makeBaseRequest("message_plugin"),
+		BaseRequest: *api.MakeBaseRequest("message_plugin"),
 		Request:     request,
 	}
 }
 
-func (api *DefaultAdminAPI) makeSessionRequest(action string, sessionID uint64) *SessionRequest {
+func (api *DefaultAdminAPI) MakeSessionRequest(action string, sessionID uint64) *SessionRequest {
 	return &SessionRequest{
-		BaseRequest: *api.makeBaseRequest(action),
+		BaseRequest: *api.MakeBaseRequest(action),
 		SessionID:   sessionID,
 	}
 }
 
-func (api *DefaultAdminAPI) makeHandleRequest(action string, sessionID, handleID uint64) *HandleRequest {
+func (api *DefaultAdminAPI) MakeHandleRequest(action string, sessionID, handleID uint64) *HandleRequest {
 	return &HandleRequest{
-		SessionRequest: *api.makeSessionRequest(action, sessionID),
+		SessionRequest: *api.MakeSessionRequest(action, sessionID),
 		HandleID:       handleID,
 	}
 }
+
+func (api *DefaultAdminAPI) MakePcapRequest(action string, sessionID, handleID uint64, folder, filename string, truncate int) *PcapRequest {
+	return &PcapRequest{
+		HandleRequest: *api.MakeHandleRequest(action, sessionID, handleID),
+		Folder:        folder,
+		Filename:      filename,
+		Truncate:      truncate,
+	}
+}
+
+func (api *DefaultAdminAPI) MakeMessageHandleRequest(sessionID, handleID uint64, request plugins.PluginRequest) *MessageHandleRequest {
+	return &MessageHandleRequest{
+		HandleRequest: *api.MakeHandleRequest("message_handle", sessionID, handleID),
+		Request:       request,
+	}
+}
@@ -3,19 +3,33 @@ package admin
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/tatsujin1/janus-go"
+	"github.com/tatsujin1/janus-go/janustest"
 	"github.com/tatsujin1/janus-go/plugins"
 )
 
+func newTestServer() *janustest.Server {
+	server := janustest.NewServer()
+	server.AdminSecret = "janus-go"
+	server.TokenAuth = true
+	server.HandlePlugin("janus.plugin.videoroom", janustest.Rooms("videoroom"))
+	server.HandlePlugin("janus.plugin.textroom", janustest.Rooms("textroom"))
+	return server
+}
+
 func TestAdminTokens(t *testing.T) {
-	api, err := NewAdminAPI("http://localhost:7088/admin", "janus-go")
+	server := newTestServer()
+	defer server.Close()
+
+	api, err := NewAdminAPI(server.AdminURL, "janus-go")
 	noError(t, err)
 
-	resp, err := api.AddToken("test-token", []string{"janus.plugin.videoroom"})
+	allowed, err := api.AddToken("test-token", []string{"janus.plugin.videoroom"})
 	noError(t, err)
-	if resp == nil {
-		t.Errorf("resp is nil")
+	if len(allowed) != 1 || allowed[0] != "janus.plugin.videoroom" {
+		t.Errorf("unexpected allowed plugins %v", allowed)
 	}
 
 	st := findToken(t, api, "test-token")
@@ -31,8 +45,11 @@ func TestAdminTokens(t *testing.T) {
 		}
 	}
 
-	resp, err = api.AllowToken("test-token", []string{"janus.plugin.echotest"})
+	allowed, err = api.AllowToken("test-token", []string{"janus.plugin.echotest"})
 	noError(t, err)
+	if len(allowed) != 2 {
+		t.Errorf("expecting 2 allowed plugins got %d", len(allowed))
+	}
 
 	st = findToken(t, api, "test-token")
 	if st == nil {
@@ -43,7 +60,7 @@ func TestAdminTokens(t *testing.T) {
 		}
 	}
 
-	resp, err = api.DisallowToken("test-token", []string{"janus.plugin.videoroom"})
+	_, err = api.DisallowToken("test-token", []string{"janus.plugin.videoroom"})
 	noError(t, err)
 
 	st = findToken(t, api, "test-token")
@@ -59,7 +76,7 @@ func TestAdminTokens(t *testing.T) {
 		}
 	}
 
-	resp, err = api.RemoveToken("test-token")
+	err = api.RemoveToken("test-token")
 	noError(t, err)
 
 	st = findToken(t, api, "test-token")
@@ -69,16 +86,10 @@ func TestAdminTokens(t *testing.T) {
 }
 
 func findToken(t *testing.T, api AdminAPI, token string) *StoredToken {
-	resp, err := api.ListTokens()
+	tokens, err := api.ListTokens()
 	noError(t, err)
 
-	tokens, ok := resp.(*ListTokensResponse)
-	if !ok {
-		t.Errorf("wrong type: ListTokensResponse != %v", resp)
-		return nil
-	}
-
-	for _, x := range tokens.Data["tokens"] {
+	for _, x := range tokens {
 		if x.Token == token {
 			return x
 		}
@@ -88,11 +99,14 @@ func findToken(t *testing.T, api AdminAPI, token string) *StoredToken {
 }
 
 func TestDefaultAdminAPI_ListSessions(t *testing.T) {
-	client, err := janus.Connect("ws://localhost:8188/")
+	server := newTestServer()
+	defer server.Close()
+
+	client, err := janus.Connect(server.URL)
 	noError(t, err)
 	defer client.Close()
 
-	api, err := NewAdminAPI("http://localhost:7088/admin", "janus-go")
+	api, err := NewAdminAPI(server.AdminURL, "janus-go")
 	noError(t, err)
 
 	_, err = api.AddToken("test-token", []string{})
@@ -104,31 +118,138 @@ func TestDefaultAdminAPI_ListSessions(t *testing.T) {
 	noError(t, err)
 	defer session.Destroy()
 
-	resp, err := api.ListSessions()
+	sessions, err := api.ListSessions()
 	noError(t, err)
+	if len(sessions) != 1 {
+		t.Errorf("expecting exactly 1 session, found %d", len(sessions))
+		return
+	}
+
+	if session.ID != sessions[0] {
+		t.Errorf("sessionID mismatch, expected %d got %d", session.ID, sessions[0])
+		return
+	}
 
+	// The raw response stays reachable through Request.
+	resp, err := api.Request(api.MakeBaseRequest("list_sessions"))
+	noError(t, err)
 	tResp, ok := resp.(*ListSessionsResponse)
 	if !ok {
 		t.Errorf("wrong type: ListSessionsResponse != %v", resp)
 		return
 	}
-	if len(tResp.Sessions) != 1 {
-		t.Errorf("expecting exactly 1 session, found %d", len(tResp.Sessions))
-		return
+	if len(tResp.Sessions) != 1 || tResp.Sessions[0] != session.ID {
+		t.Errorf("unexpected sessions %v", tResp.Sessions)
 	}
+}
 
-	if session.ID != tResp.Sessions[0] {
-		t.Errorf("sessionID mismatch, expected %d got %d", session.ID, tResp.Sessions[0])
-		return
+func TestDefaultAdminAPI_ServerSettings(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	client, err := janus.Connect(server.URL)
+	noError(t, err)
+	defer client.Close()
+
+	api, err := NewAdminAPI(server.AdminURL, "janus-go")
+	noError(t, err)
+
+	info, err := api.Info()
+	noError(t, err)
+	if info.VersionString != "1.0.0" {
+		t.Errorf("unexpected server info %+v", info)
+	}
+	if _, ok := info.Plugins["janus.plugin.videoroom"]; !ok {
+		t.Errorf("videoroom plugin missing from %v", info.Plugins)
+	}
+	noError(t, api.Ping())
+
+	status, err := api.GetStatus()
+	noError(t, err)
+	if !status.TokenAuth || status.LogLevel != LogInfo || status.SessionTimeout != 60 {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	level, err := api.SetLogLevel(LogDebug)
+	noError(t, err)
+	if level != LogDebug {
+		t.Errorf("expecting log level %d got %d", LogDebug, level)
+	}
+	timeout, err := api.SetSessionTimeout(120)
+	noError(t, err)
+	if timeout != 120 {
+		t.Errorf("expecting session timeout 120 got %d", timeout)
+	}
+	timestamps, err := api.SetLogTimestamps(true)
+	noError(t, err)
+	if !timestamps {
+		t.Error("log timestamps are expected to be enabled")
+	}
+	_, err = api.SetLogColors(false)
+	noError(t, err)
+	_, err = api.SetLockingDebug(true)
+	noError(t, err)
+	_, err = api.SetRefcountDebug(true)
+	noError(t, err)
+	_, err = api.SetLibniceDebug(true)
+	noError(t, err)
+	_, err = api.SetMinNackQueue(500)
+	noError(t, err)
+	_, err = api.SetNoMediaTimer(5)
+	noError(t, err)
+	threshold, err := api.SetSlowlinkThreshold(4)
+	noError(t, err)
+	if threshold != 4 {
+		t.Errorf("expecting slowlink threshold 4 got %d", threshold)
+	}
+
+	status, err = api.GetStatus()
+	noError(t, err)
+	expected := ServerStatus{
+		TokenAuth:         true,
+		SessionTimeout:    120,
+		CandidatesTimeout: 45,
+		LogLevel:          LogDebug,
+		LogTimestamps:     true,
+		LockingDebug:      true,
+		RefcountDebug:     true,
+		LibniceDebug:      true,
+		MinNackQueue:      500,
+		NoMediaTimer:      5,
+		SlowlinkThreshold: 4,
+	}
+	if *status != expected {
+		t.Errorf("unexpected status after settings %+v", status)
+	}
+
+	_, err = api.AddToken("test-token", []string{})
+	noError(t, err)
+	client.Token = "test-token"
+
+	accept, err := api.AcceptNewSessions(false)
+	noError(t, err)
+	if accept {
+		t.Error("new sessions are expected to be refused")
+	}
+	if _, err := client.Create(); err == nil {
+		t.Error("expecting err on create while refusing new sessions")
 	}
+	_, err = api.AcceptNewSessions(true)
+	noError(t, err)
+	session, err := client.Create()
+	noError(t, err)
+	defer session.Destroy()
 }
 
 func TestDefaultAdminAPI_MessagePlugin_Videoroom(t *testing.T) {
-	client, err := janus.Connect("ws://localhost:8188/")
+	server := newTestServer()
+	defer server.Close()
+
+	client, err := janus.Connect(server.URL)
 	noError(t, err)
 	defer client.Close()
 
-	api, err := NewAdminAPI("http://localhost:7088/admin", "janus-go")
+	api, err := NewAdminAPI(server.AdminURL, "janus-go")
 	noError(t, err)
 
 	_, err = api.AddToken("test-token", []string{})
@@ -264,12 +385,191 @@ func findVideoroom(t *testing.T, api AdminAPI, requestFactory *plugins.Videoroom
 	return nil
 }
 
+func TestDefaultAdminAPI_MessagePlugin_VideoroomModeration(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	rooms := janustest.Rooms("videoroom")
+	server.HandlePlugin("janus.plugin.videoroom", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		reply := func(data map[string]interface{}) *janustest.PluginResponse {
+			return &janustest.PluginResponse{Data: data}
+		}
+		switch msg.Body["request"] {
+		case "listparticipants":
+			return reply(map[string]interface{}{
+				"videoroom": "participants",
+				"room":      msg.Body["room"],
+				"participants": []interface{}{
+					map[string]interface{}{"id": 42, "display": "alice", "publisher": true},
+				},
+			})
+		case "kick":
+			if msg.Body["id"] != 42.0 {
+				return reply(map[string]interface{}{"videoroom": "event", "error_code": 428, "error": "No such user"})
+			}
+			return reply(map[string]interface{}{"videoroom": "success"})
+		case "rtp_forward":
+			streams, _ := msg.Body["streams"].([]interface{})
+			forwarders := []interface{}{}
+			for i, stream := range streams {
+				stream := stream.(map[string]interface{})
+				forwarders = append(forwarders, map[string]interface{}{"stream_id": i + 1, "host": msg.Body["host"], "port": stream["port"]})
+			}
+			return reply(map[string]interface{}{
+				"videoroom":    "rtp_forward",
+				"room":         msg.Body["room"],
+				"publisher_id": msg.Body["publisher_id"],
+				"forwarders":   forwarders,
+			})
+		}
+		return rooms(msg)
+	})
+
+	api, err := NewAdminAPI(server.AdminURL, "janus-go")
+	noError(t, err)
+
+	requestFactory := plugins.MakeVideoroomRequestFactory("supersecret")
+
+	resp, err := api.MessagePlugin(requestFactory.ExistsRequest(99))
+	noError(t, err)
+	exists, ok := resp.(*plugins.VideoroomExistsResponse)
+	if !ok {
+		t.Fatalf("wrong type: VideoroomExistsResponse != %v", resp)
+	}
+	if exists.Exists {
+		t.Error("Videoroom is not expected to exist")
+	}
+
+	resp, err = api.MessagePlugin(requestFactory.ListParticipantsRequest(99))
+	noError(t, err)
+	participants, ok := resp.(*plugins.VideoroomParticipantsResponse)
+	if !ok {
+		t.Fatalf("wrong type: VideoroomParticipantsResponse != %v", resp)
+	}
+	if len(participants.Participants) != 1 || participants.Participants[0].ID != 42 || !participants.Participants[0].Publisher {
+		t.Errorf("unexpected participants %v", participants.Participants)
+	}
+
+	resp, err = api.MessagePlugin(requestFactory.KickRequest(99, "test_secret", 42))
+	noError(t, err)
+	if _, ok := resp.(*plugins.VideoroomSuccessResponse); !ok {
+		t.Errorf("wrong type: VideoroomSuccessResponse != %v", resp)
+	}
+	_, err = api.MessagePlugin(requestFactory.KickRequest(99, "test_secret", 43))
+	if pErr, ok := err.(*plugins.VideoroomErrorResponse); !ok || pErr.Code != 428 {
+		t.Errorf("expecting err on kick of non existing participant, got %v", err)
+	}
+
+	resp, err = api.MessagePlugin(requestFactory.RtpForwardRequest(99, "test_secret", 42, "127.0.0.1", []*plugins.VideoroomForwardStream{
+		{Mid: "0", Port: 5002},
+		{Mid: "1", Port: 5004},
+	}))
+	noError(t, err)
+	forward, ok := resp.(*plugins.VideoroomRtpForwardResponse)
+	if !ok {
+		t.Fatalf("wrong type: VideoroomRtpForwardResponse != %v", resp)
+	}
+	if forward.PublisherID != 42 || len(forward.Forwarders) != 2 || forward.Forwarders[1].Port != 5004 {
+		t.Errorf("unexpected rtp_forward response %+v", forward)
+	}
+}
+
+func TestDefaultAdminAPI_MessagePlugin_AudiobridgeModeration(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	rooms := janustest.Rooms("audiobridge")
+	playing := map[string]bool{}
+	server.HandlePlugin("janus.plugin.audiobridge", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		reply := func(data map[string]interface{}) *janustest.PluginResponse {
+			data["audiobridge"] = "success"
+			data["room"] = msg.Body["room"]
+			return &janustest.PluginResponse{Data: data}
+		}
+		fileID, _ := msg.Body["file_id"].(string)
+		switch msg.Body["request"] {
+		case "mute", "unmute", "mute_room", "unmute_room", "kick_all":
+			return reply(map[string]interface{}{})
+		case "play_file":
+			if fileID == "" {
+				fileID = "announcement"
+			}
+			playing[fileID] = true
+			return reply(map[string]interface{}{"file_id": fileID})
+		case "is_playing":
+			return reply(map[string]interface{}{"file_id": fileID, "playing": playing[fileID]})
+		case "stop_file":
+			delete(playing, fileID)
+			return reply(map[string]interface{}{"file_id": fileID})
+		case "rtp_forward":
+			return reply(map[string]interface{}{"stream_id": 1, "host": msg.Body["host"], "port": msg.Body["port"]})
+		}
+		return rooms(msg)
+	})
+
+	api, err := NewAdminAPI(server.AdminURL, "janus-go")
+	noError(t, err)
+
+	requestFactory := plugins.MakeAudiobridgeRequestFactory("supersecret")
+
+	resp, err := api.MessagePlugin(requestFactory.MuteRequest(99, "test_secret", 42, true))
+	noError(t, err)
+	if _, ok := resp.(*plugins.AudiobridgeSuccessResponse); !ok {
+		t.Errorf("wrong type: AudiobridgeSuccessResponse != %v", resp)
+	}
+
+	resp, err = api.MessagePlugin(requestFactory.PlayFileRequest(99, "test_secret", "", "/tmp/announcement.opus", false))
+	noError(t, err)
+	file, ok := resp.(*plugins.AudiobridgeFileResponse)
+	if !ok {
+		t.Fatalf("wrong type: AudiobridgeFileResponse != %v", resp)
+	}
+
+	resp, err = api.MessagePlugin(requestFactory.IsPlayingRequest(99, "test_secret", file.FileID))
+	noError(t, err)
+	isPlaying, ok := resp.(*plugins.AudiobridgeIsPlayingResponse)
+	if !ok {
+		t.Fatalf("wrong type: AudiobridgeIsPlayingResponse != %v", resp)
+	}
+	if !isPlaying.Playing || isPlaying.FileID != "announcement" {
+		t.Errorf("unexpected is_playing response %+v", isPlaying)
+	}
+
+	_, err = api.MessagePlugin(requestFactory.StopFileRequest(99, "test_secret", file.FileID))
+	noError(t, err)
+	resp, err = api.MessagePlugin(requestFactory.IsPlayingRequest(99, "test_secret", file.FileID))
+	noError(t, err)
+	if resp.(*plugins.AudiobridgeIsPlayingResponse).Playing {
+		t.Error("file is not expected to play after stop_file")
+	}
+
+	resp, err = api.MessagePlugin(requestFactory.RtpForwardRequest(99, "test_secret", &plugins.AudiobridgeRtpForward{Host: "127.0.0.1", Port: 5002}))
+	noError(t, err)
+	forward, ok := resp.(*plugins.AudiobridgeRtpForwardResponse)
+	if !ok {
+		t.Fatalf("wrong type: AudiobridgeRtpForwardResponse != %v", resp)
+	}
+	if forward.StreamID != 1 || forward.Port != 5002 {
+		t.Errorf("unexpected rtp_forward response %+v", forward)
+	}
+
+	_, err = api.MessagePlugin(requestFactory.ExistsRequest(99))
+	noError(t, err)
+	_, err = api.MessagePlugin(requestFactory.ListParticipantsRequest(99))
+	if err == nil {
+		t.Error("expecting err on listparticipants of non existing audiobridge")
+	}
+}
+
 func TestDefaultAdminAPI_MessagePlugin_Textroom(t *testing.T) {
-	client, err := janus.Connect("ws://localhost:8188/")
+	server := newTestServer()
+	defer server.Close()
+
+	client, err := janus.Connect(server.URL)
 	noError(t, err)
 	defer client.Close()
 
-	api, err := NewAdminAPI("http://localhost:7088/admin", "janus-go")
+	api, err := NewAdminAPI(server.AdminURL, "janus-go")
 	noError(t, err)
 
 	_, err = api.AddToken("test-token", []string{})
@@ -367,12 +667,137 @@ func findTextroom(t *testing.T, api AdminAPI, requestFactory *plugins.TextroomRe
 	return nil
 }
 
+func TestDefaultAdminAPI_MessagePlugin_Streaming(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	mountpoints := map[float64]map[string]interface{}{}
+	server.HandlePlugin("janus.plugin.streaming", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		reply := func(result string, data map[string]interface{}) *janustest.PluginResponse {
+			data["streaming"] = result
+			return &janustest.PluginResponse{Data: data}
+		}
+		id, _ := msg.Body["id"].(float64)
+		request := msg.Body["request"]
+		switch request {
+		case "list":
+			list := []interface{}{}
+			for _, mp := range mountpoints {
+				list = append(list, map[string]interface{}{"id": mp["id"], "type": mp["type"], "description": mp["description"], "enabled": mp["enabled"]})
+			}
+			return reply("list", map[string]interface{}{"list": list})
+		case "create":
+			mountpoints[id] = map[string]interface{}{"id": id, "type": msg.Body["type"], "description": msg.Body["description"], "enabled": true, "secret": msg.Body["secret"]}
+			return reply("created", map[string]interface{}{
+				"create":    msg.Body["name"],
+				"permanent": msg.Body["permanent"],
+				"stream":    map[string]interface{}{"id": id, "type": msg.Body["type"], "audio_port": msg.Body["audioport"]},
+			})
+		}
+		mp, ok := mountpoints[id]
+		if !ok {
+			return reply("event", map[string]interface{}{"error_code": 455, "error": "No such mountpoint/stream"})
+		}
+		if msg.Body["secret"] != mp["secret"] {
+			return reply("event", map[string]interface{}{"error_code": 457, "error": "Unauthorized (wrong secret)"})
+		}
+		switch request {
+		case "info":
+			return reply("info", map[string]interface{}{"info": mp})
+		case "edit":
+			mp["description"] = msg.Body["new_description"]
+			return reply("edited", map[string]interface{}{"id": id, "permanent": msg.Body["permanent"]})
+		case "destroy":
+			delete(mountpoints, id)
+			return reply("destroyed", map[string]interface{}{"id": id})
+		case "enable", "disable":
+			mp["enabled"] = request == "enable"
+			return reply("ok", map[string]interface{}{})
+		case "recording":
+			mp["audio_file"] = msg.Body["audio"]
+			return reply("ok", map[string]interface{}{})
+		}
+		return nil
+	})
+
+	api, err := NewAdminAPI(server.AdminURL, "janus-go")
+	noError(t, err)
+
+	requestFactory := plugins.MakeStreamingRequestFactory("supersecret")
+
+	mountpoint := &plugins.StreamingRtpMountpoint{
+		StreamingMountpoint: plugins.StreamingMountpoint{
+			ID:          77,
+			Name:        "test-stream",
+			Description: "test stream",
+			Secret:      "test_secret",
+			Audio:       true,
+		},
+		AudioPort:   5002,
+		AudioPT:     111,
+		AudioRtpMap: "opus/48000/2",
+	}
+	resp, err := api.MessagePlugin(requestFactory.CreateRtpRequest(mountpoint, false))
+	noError(t, err)
+	created, ok := resp.(*plugins.StreamingCreateResponse)
+	if !ok {
+		t.Fatalf("wrong type: StreamingCreateResponse != %v", resp)
+	}
+	if created.Name != "test-stream" || created.Stream.ID != 77 || created.Stream.AudioPort != 5002 {
+		t.Errorf("unexpected create response %+v", created)
+	}
+
+	resp, err = api.MessagePlugin(requestFactory.ListRequest())
+	noError(t, err)
+	list, ok := resp.(*plugins.StreamingListResponse)
+	if !ok {
+		t.Fatalf("wrong type: StreamingListResponse != %v", resp)
+	}
+	if len(list.Mountpoints) != 1 || list.Mountpoints[0].Type != "rtp" || !list.Mountpoints[0].Enabled {
+		t.Errorf("unexpected list response %+v", list.Mountpoints)
+	}
+
+	_, err = api.MessagePlugin(requestFactory.EditRequest(&plugins.StreamingMountpointForEdit{ID: 77, Description: "edited"}, false, "test_secret"))
+	noError(t, err)
+	_, err = api.MessagePlugin(requestFactory.DisableRequest(77, "test_secret"))
+	noError(t, err)
+	resp, err = api.MessagePlugin(requestFactory.StartRecordingRequest(77, "test_secret", "/tmp/audio.mjr", "", ""))
+	noError(t, err)
+	if _, ok := resp.(*plugins.StreamingOkResponse); !ok {
+		t.Errorf("wrong type: StreamingOkResponse != %v", resp)
+	}
+
+	resp, err = api.MessagePlugin(requestFactory.InfoRequest(77, "test_secret"))
+	noError(t, err)
+	info, ok := resp.(*plugins.StreamingInfoResponse)
+	if !ok {
+		t.Fatalf("wrong type: StreamingInfoResponse != %v", resp)
+	}
+	if info.Info.Description != "edited" || info.Info.Enabled || info.Info.AudioFile != "/tmp/audio.mjr" {
+		t.Errorf("unexpected info response %+v", info.Info)
+	}
+
+	_, err = api.MessagePlugin(requestFactory.DestroyRequest(77, false, "wrong"))
+	if err == nil {
+		t.Error("expecting err on destroy with a wrong secret")
+	}
+	_, err = api.MessagePlugin(requestFactory.DestroyRequest(77, false, "test_secret"))
+	noError(t, err)
+	_, err = api.MessagePlugin(requestFactory.InfoRequest(77, "test_secret"))
+	if err == nil {
+		t.Error("expecting err on info of destroyed mountpoint")
+	}
+}
+
 func TestDefaultAdminAPI_ListHandles(t *testing.T) {
-	client, err := janus.Connect("ws://localhost:8188/")
+	server := newTestServer()
+	defer server.Close()
+
+	client, err := janus.Connect(server.URL)
 	noError(t, err)
 	defer client.Close()
 
-	api, err := NewAdminAPI("http://localhost:7088/admin", "janus-go")
+	api, err := NewAdminAPI(server.AdminURL, "janus-go")
 	noError(t, err)
 
 	_, err = api.AddToken("test-token", []string{})
@@ -388,34 +813,28 @@ func TestDefaultAdminAPI_ListHandles(t *testing.T) {
 	noError(t, err)
 	defer handle.Detach()
 
-	resp, err := api.ListHandles(session.ID)
+	handles, err := api.ListHandles(session.ID)
 	noError(t, err)
-	if resp == nil {
-		t.Error("resp is nil")
-		return
-	}
-	tResp, ok := resp.(*ListHandlesResponse)
-	if !ok {
-		t.Errorf("wrong type: ListHandlesResponse != %v", resp)
-		return
-	}
-	if len(tResp.Handles) != 1 {
-		t.Errorf("expecting exactly 1 handle, found %d", len(tResp.Handles))
+	if len(handles) != 1 {
+		t.Errorf("expecting exactly 1 handle, found %d", len(handles))
 		return
 	}
 
-	if handle.ID != tResp.Handles[0] {
-		t.Errorf("handleID mismatch, expected %d got %d", handle.ID, tResp.Handles[0])
+	if handle.ID != handles[0] {
+		t.Errorf("handleID mismatch, expected %d got %d", handle.ID, handles[0])
 		return
 	}
 }
 
 func TestDefaultAdminAPI_HandleInfo(t *testing.T) {
-	client, err := janus.Connect("ws://localhost:8188/")
+	server := newTestServer()
+	defer server.Close()
+
+	client, err := janus.Connect(server.URL)
 	noError(t, err)
 	defer client.Close()
 
-	api, err := NewAdminAPI("http://localhost:7088/admin", "janus-go")
+	api, err := NewAdminAPI(server.AdminURL, "janus-go")
 	noError(t, err)
 
 	_, err = api.AddToken("test-token", []string{})
@@ -431,20 +850,111 @@ func TestDefaultAdminAPI_HandleInfo(t *testing.T) {
 	noError(t, err)
 	defer handle.Detach()
 
-	resp, err := api.HandleInfo(session.ID, handle.ID)
+	info, err := api.HandleInfo(session.ID, handle.ID)
 	noError(t, err)
-	if resp == nil {
-		t.Error("resp is nil")
+	if info == nil {
+		t.Error("expected info to not be nil")
 		return
 	}
-	tResp, ok := resp.(*HandleInfoResponse)
-	if !ok {
-		t.Errorf("wrong type: HandleInfoResponse != %v", resp)
-		return
+	if info.HandleID != handle.ID || info.Plugin != "janus.plugin.videoroom" {
+		t.Errorf("unexpected handle %d of plugin %s", info.HandleID, info.Plugin)
 	}
-	if tResp.Info == nil {
-		t.Error("expected info to not be nil")
-		return
+	if info.Flags == nil || !info.Flags.Ready || info.IceRole != "controlled" || info.PluginSpecific["type"] != "publisher" {
+		t.Errorf("unexpected handle info %+v", info)
+	}
+	if info.Sdps == nil || info.Sdps.Profile != "UDP/TLS/RTP/SAVPF" {
+		t.Errorf("unexpected sdps %+v", info.Sdps)
+	}
+	if len(info.Streams) != 1 || len(info.Streams[0].Components) != 1 {
+		t.Fatalf("expecting exactly 1 stream with 1 component, got %+v", info.Streams)
+	}
+	stream := info.Streams[0]
+	if stream.Ssrc["video"] != 2222 || stream.Codecs.VideoCodec != "vp8" || stream.RtcpStats["audio"].Rtt != 20 {
+		t.Errorf("unexpected stream %+v", stream)
+	}
+	component := stream.Components[0]
+	if component.State != "connected" || component.SelectedPair == "" {
+		t.Errorf("unexpected ICE state %s, selected pair %q", component.State, component.SelectedPair)
+	}
+	if component.Dtls == nil || component.Dtls.DtlsState != "connected" || !component.Dtls.Valid {
+		t.Errorf("unexpected DTLS state %+v", component.Dtls)
+	}
+	if component.InStats == nil || component.InStats.VideoPackets != 1000 || component.OutStats.AudioPackets != 400 {
+		t.Errorf("unexpected media stats in %+v, out %+v", component.InStats, component.OutStats)
+	}
+}
+
+func TestDefaultAdminAPI_Intervention(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	client, err := janus.Connect(server.URL)
+	noError(t, err)
+	defer client.Close()
+
+	api, err := NewAdminAPI(server.AdminURL, "janus-go")
+	noError(t, err)
+
+	_, err = api.AddToken("test-token", []string{})
+	noError(t, err)
+	client.Token = "test-token"
+
+	session, err := client.Create()
+	noError(t, err)
+
+	handle, err := session.Attach("janus.plugin.videoroom")
+	noError(t, err)
+	other, err := session.Attach("janus.plugin.videoroom")
+	noError(t, err)
+
+	noError(t, api.StartPcap(session.ID, handle.ID, "/tmp", "capture.pcap", 100))
+	noError(t, api.StopPcap(session.ID, handle.ID))
+	noError(t, api.StartText2pcap(session.ID, handle.ID, "", "", 0))
+	noError(t, api.StopText2pcap(session.ID, handle.ID))
+
+	requestFactory := plugins.MakeVideoroomRequestFactory("supersecret")
+	resp, err := api.MessageHandle(session.ID, handle.ID, requestFactory.ListRequest())
+	noError(t, err)
+	if _, ok := resp.(*plugins.VideoroomListResponse); !ok {
+		t.Errorf("wrong type: VideoroomListResponse != %v", resp)
+	}
+
+	noError(t, api.HangupWebRTC(session.ID, handle.ID))
+	select {
+	case event := <-handle.Events:
+		if hangup, ok := event.(*janus.HangupMsg); !ok || hangup.Reason != "Admin API" {
+			t.Errorf("expecting hangup event, got %v", event)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for the hangup event")
+	}
+
+	noError(t, api.DetachHandle(session.ID, other.ID))
+	select {
+	case event := <-other.Events:
+		if _, ok := event.(*janus.DetachedMsg); !ok {
+			t.Errorf("expecting detached event, got %v", event)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for the detached event")
+	}
+	handles, err := api.ListHandles(session.ID)
+	noError(t, err)
+	if len(handles) != 1 || handles[0] != handle.ID {
+		t.Errorf("expecting only handle %d, got %v", handle.ID, handles)
+	}
+	if err := api.HangupWebRTC(session.ID, other.ID); err == nil {
+		t.Error("expecting err on hangup of detached handle")
+	}
+
+	noError(t, api.DestroySession(session.ID))
+	sessions, err := api.ListSessions()
+	noError(t, err)
+	if len(sessions) != 0 {
+		t.Errorf("expecting no session after destroy_session, got %v", sessions)
+	}
+	if err := api.DestroySession(session.ID); err == nil {
+		t.Error("expecting err on destroy of destroyed session")
 	}
 }
 
@@ -2,12 +2,16 @@ package admin
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/tatsujin1/janus-go"
 )
 
 type TransportError struct {
@@ -24,6 +28,16 @@ type Transport interface {
 	Close() error
 }
 
+// ContextTransport is implemented by the transports which can give up a
+// request when a context is done.
+type ContextTransport interface {
+	Transport
+	RequestContext(context.Context, APIRequest) (interface{}, error)
+}
+
+// Timeout after which a request without an earlier deadline is given up.
+const requestTimeout = 10 * time.Second
+
 type HttpTransport struct {
 	client *http.Client
 	url    string
@@ -40,18 +54,22 @@ func NewHttpTransport(url string) *HttpTransport {
 			TLSHandshakeTimeout: 5 * time.Second,
 		},
 
-		Timeout: 10 * time.Second,
+		Timeout: requestTimeout,
 	}
 	return c
 }
 
 func (t *HttpTransport) Request(r APIRequest) (interface{}, error) {
+	return t.RequestContext(context.Background(), r)
+}
+
+func (t *HttpTransport) RequestContext(ctx context.Context, r APIRequest) (interface{}, error) {
 	b, err := json.Marshal(r.Payload())
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, t.url+r.Endpoint(), bytes.NewBuffer(b))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url+r.Endpoint(), bytes.NewBuffer(b))
 	if err != nil {
 		return nil, err
 	}
@@ -61,11 +79,11 @@ func (t *HttpTransport) Request(r APIRequest) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode >= http.StatusBadRequest {
 		return nil, &TransportError{Code: resp.StatusCode, Msg: resp.Status}
 	}
 
-	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -86,3 +104,108 @@ func (t *HttpTransport) Request(r APIRequest) (interface{}, error) {
 func (t *HttpTransport) Close() error {
 	return nil
 }
+
+type UnixTransport struct {
+	conn *janus.UnixTransport
+	mu   sync.Mutex
+}
+
+func NewUnixTransport(url string) (*UnixTransport, error) {
+	conn, err := janus.DialUnix(url)
+	if err != nil {
+		return nil, err
+	}
+	return &UnixTransport{conn: conn}, nil
+}
+
+func (t *UnixTransport) Request(r APIRequest) (interface{}, error) {
+	return t.RequestContext(context.Background(), r)
+}
+
+// RequestContext sends a request and waits for its response, for at most
+// 10 seconds like HttpTransport, or until ctx is done.
+func (t *UnixTransport) RequestContext(ctx context.Context, r APIRequest) (interface{}, error) {
+	payload := r.Payload()
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// A lost response must not block the requests queued behind this one.
+	deadline := time.Now().Add(requestTimeout)
+	ctxDeadline := false
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline, ctxDeadline = d, true
+	}
+	// contextErr reports the error of ctx for failures caused by it, as the
+	// socket can time out slightly before ctx does.
+	contextErr := func(err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() && ctxDeadline {
+			return context.DeadlineExceeded
+		}
+		return err
+	}
+	if err := t.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	defer t.conn.SetDeadline(time.Time{})
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	defer func() {
+		close(stop)
+		<-stopped
+	}()
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			// unblock the pending read
+			t.conn.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	if err := t.conn.Send(b); err != nil {
+		return nil, contextErr(err)
+	}
+
+	transaction, _ := payload["transaction"].(string)
+	for {
+		body, err := t.conn.Receive()
+		if err != nil {
+			return nil, contextErr(err)
+		}
+
+		// skip stale responses to requests which failed on our side
+		var base BaseAMResponse
+		if err := json.Unmarshal(body, &base); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal: %w", err)
+		}
+		if base.ID != transaction {
+			continue
+		}
+
+		pResp, err := ParseAMResponse(r, body)
+		if err != nil {
+			return nil, err
+		}
+
+		switch pResp := pResp.(type) {
+		case error:
+			return nil, pResp
+		default:
+			return pResp, nil
+		}
+	}
+}
+
+func (t *UnixTransport) Close() error {
+	return t.conn.Close()
+}
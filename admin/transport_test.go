@@ -0,0 +1,148 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUnixTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "janus-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "admin")
+	l, err := net.Listen("unixpacket", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		buf := make([]byte, 4096)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		var req map[string]interface{}
+		if err := json.Unmarshal(buf[:n], &req); err != nil {
+			t.Error(err)
+			return
+		}
+		if req["janus"] != "list_sessions" || req["admin_secret"] != "janus-go" {
+			t.Errorf("unexpected request %v", req)
+		}
+		// a stale response must be skipped
+		fmt.Fprint(conn, `{"janus":"success","transaction":"stale","sessions":[]}`)
+		fmt.Fprintf(conn, `{"janus":"success","transaction":"%s","sessions":[1,2]}`, req["transaction"])
+	}()
+
+	api, err := NewAdminAPI("unix://"+path, "janus-go")
+	noError(t, err)
+	defer api.Close()
+
+	sessions, err := api.ListSessions()
+	noError(t, err)
+	if len(sessions) != 2 {
+		t.Errorf("expecting 2 sessions, found %d", len(sessions))
+	}
+}
+
+func TestUnixTransport_LostResponse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "janus-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "admin")
+	l, err := net.Listen("unixpacket", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		buf := make([]byte, 4096)
+		for i := 0; ; i++ {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			var req map[string]interface{}
+			if err := json.Unmarshal(buf[:n], &req); err != nil {
+				t.Error(err)
+				return
+			}
+			// lose the response to the first request
+			if i > 0 {
+				fmt.Fprintf(conn, `{"janus":"success","transaction":"%s","sessions":[1]}`, req["transaction"])
+			}
+		}
+	}()
+
+	api, err := NewAdminAPI("unix://"+path, "janus-go")
+	noError(t, err)
+	defer api.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := api.RequestContext(ctx, api.MakeBaseRequest("list_sessions")); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	sessions, err := api.ListSessions()
+	noError(t, err)
+	if len(sessions) != 1 {
+		t.Errorf("expecting 1 session, found %d", len(sessions))
+	}
+}
+
+// plainTransport is a Transport without RequestContext.
+type plainTransport struct {
+	requests []APIRequest
+}
+
+func (t *plainTransport) Request(r APIRequest) (interface{}, error) {
+	t.requests = append(t.requests, r)
+	return &PongResponse{}, nil
+}
+
+func (t *plainTransport) Close() error {
+	return nil
+}
+
+func TestDefaultAdminAPI_PlainTransport(t *testing.T) {
+	transport := &plainTransport{}
+	api := &DefaultAdminAPI{transport: transport, secret: "janus-go"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := api.RequestContext(ctx, api.MakeBaseRequest("ping"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resp.(*PongResponse); !ok || len(transport.requests) != 1 {
+		t.Errorf("expected the request to go through Request, got %v", resp)
+	}
+}
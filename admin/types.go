@@ -49,6 +49,20 @@ func (r *TokenRequest) Payload() map[string]interface{} {
 	return m
 }
 
+// SettingRequest changes a runtime setting of the server, sending Value as
+// the parameter Name of the request (e.g. "level" for set_log_level).
+type SettingRequest struct {
+	BaseRequest
+	Name  string
+	Value interface{}
+}
+
+func (r *SettingRequest) Payload() map[string]interface{} {
+	m := r.BaseRequest.Payload()
+	m[r.Name] = r.Value
+	return m
+}
+
 type MessagePluginRequest struct {
 	BaseRequest
 	Request plugins.PluginRequest
@@ -91,6 +105,43 @@ func (r *HandleRequest) Payload() map[string]interface{} {
 	return m
 }
 
+// PcapRequest starts capturing the traffic of a handle to a pcap or
+// text2pcap file named Filename in Folder, both picked by the server if
+// empty. Packets are truncated to Truncate bytes if not 0.
+type PcapRequest struct {
+	HandleRequest
+	Folder   string
+	Filename string
+	Truncate int
+}
+
+func (r *PcapRequest) Payload() map[string]interface{} {
+	m := r.HandleRequest.Payload()
+	if r.Folder != "" {
+		m["folder"] = r.Folder
+	}
+	if r.Filename != "" {
+		m["filename"] = r.Filename
+	}
+	if r.Truncate > 0 {
+		m["truncate"] = r.Truncate
+	}
+	return m
+}
+
+// MessageHandleRequest sends a request to the plugin a handle is attached
+// to, on behalf of the handle.
+type MessageHandleRequest struct {
+	HandleRequest
+	Request plugins.PluginRequest
+}
+
+func (r *MessageHandleRequest) Payload() map[string]interface{} {
+	m := r.HandleRequest.Payload()
+	m["request"] = r.Request.Payload()
+	return m
+}
+
 type BaseAMResponse struct {
 	Type string `json:"janus"`
 	ID   string `json:"transaction"`
@@ -110,11 +161,110 @@ type SuccessAMResponse struct {
 	Data map[string]interface{} `json:"data"`
 }
 
+type InfoResponse struct {
+	BaseAMResponse
+	janus.InfoMsg
+}
+
+type PongResponse struct {
+	BaseAMResponse
+}
+
+// ServerStatus holds the runtime settings of the server, as changed by the
+// set_* requests.
+type ServerStatus struct {
+	TokenAuth             bool   `json:"token_auth"`
+	TokenAuthSecret       string `json:"token_auth_secret"`
+	APISecret             bool   `json:"api_secret"`
+	SessionTimeout        int    `json:"session_timeout"`
+	ReclaimSessionTimeout int    `json:"reclaim_session_timeout"`
+	CandidatesTimeout     int    `json:"candidates_timeout"`
+	LogLevel              int    `json:"log_level"`
+	LogTimestamps         bool   `json:"log_timestamps"`
+	LogColors             bool   `json:"log_colors"`
+	LockingDebug          bool   `json:"locking_debug"`
+	RefcountDebug         bool   `json:"refcount_debug"`
+	LibniceDebug          bool   `json:"libnice_debug"`
+	MinNackQueue          int    `json:"min_nack_queue"`
+	NackOptimizations     bool   `json:"nack-optimizations"`
+	NoMediaTimer          int    `json:"no_media_timer"`
+	SlowlinkThreshold     int    `json:"slowlink_threshold"`
+}
+
+type StatusResponse struct {
+	BaseAMResponse
+	Status *ServerStatus `json:"status"`
+}
+
+type SessionTimeoutResponse struct {
+	BaseAMResponse
+	Timeout int `json:"timeout"`
+}
+
+type LogLevelResponse struct {
+	BaseAMResponse
+	Level int `json:"level"`
+}
+
+type LogTimestampsResponse struct {
+	BaseAMResponse
+	LogTimestamps bool `json:"log_timestamps"`
+}
+
+type LogColorsResponse struct {
+	BaseAMResponse
+	LogColors bool `json:"log_colors"`
+}
+
+type LockingDebugResponse struct {
+	BaseAMResponse
+	LockingDebug bool `json:"locking_debug"`
+}
+
+type RefcountDebugResponse struct {
+	BaseAMResponse
+	RefcountDebug bool `json:"refcount_debug"`
+}
+
+type LibniceDebugResponse struct {
+	BaseAMResponse
+	LibniceDebug bool `json:"libnice_debug"`
+}
+
+type MinNackQueueResponse struct {
+	BaseAMResponse
+	MinNackQueue int `json:"min_nack_queue"`
+}
+
+type NoMediaTimerResponse struct {
+	BaseAMResponse
+	NoMediaTimer int `json:"no_media_timer"`
+}
+
+type SlowlinkThresholdResponse struct {
+	BaseAMResponse
+	SlowlinkThreshold int `json:"slowlink_threshold"`
+}
+
+type AcceptNewSessionsResponse struct {
+	BaseAMResponse
+	Accept bool `json:"accept"`
+}
+
 type StoredToken struct {
 	Token   string   `json:"token"`
 	Plugins []string `json:"allowed_plugins"`
 }
 
+// TokenResponse answers the add_token, allow_token and disallow_token
+// requests, with the plugins the token is allowed to use.
+type TokenResponse struct {
+	BaseAMResponse
+	Data struct {
+		Plugins []string `json:"plugins"`
+	} `json:"data"`
+}
+
 type ListTokensResponse struct {
 	BaseAMResponse
 	Data map[string][]*StoredToken `json:"data"`
@@ -135,6 +285,29 @@ type SessionResponse struct {
 	SessionID uint64 `json:"session_id"`
 }
 
+type DestroySessionResponse struct {
+	BaseAMResponse
+}
+
+type DetachHandleResponse struct {
+	BaseAMResponse
+}
+
+type HangupWebRTCResponse struct {
+	BaseAMResponse
+}
+
+// CaptureResponse answers the requests starting or stopping a pcap or
+// text2pcap capture.
+type CaptureResponse struct {
+	BaseAMResponse
+}
+
+type MessageHandleResponse struct {
+	BaseAMResponse
+	Response map[string]interface{} `json:"response"`
+}
+
 type ListHandlesResponse struct {
 	SessionResponse
 	Handles []uint64 `json:"handles"`
@@ -147,17 +320,176 @@ type HandleResponse struct {
 
 type HandleInfoResponse struct {
 	HandleResponse
-	Info map[string]interface{} `json:"info"`
+	Info *HandleInfo `json:"info"`
+}
+
+// HandleInfo describes a handle and the state of its PeerConnection.
+// PluginSpecific holds the state of the handle in its plugin, whose content
+// depends on the plugin.
+type HandleInfo struct {
+	SessionID           uint64                 `json:"session_id"`
+	SessionLastActivity int64                  `json:"session_last_activity"`
+	SessionTimeout      int                    `json:"session_timeout"`
+	SessionTransport    string                 `json:"session_transport"`
+	HandleID            uint64                 `json:"handle_id"`
+	OpaqueID            string                 `json:"opaque_id"`
+	LoopRunning         bool                   `json:"loop-running"`
+	Created             int64                  `json:"created"`
+	CurrentTime         int64                  `json:"current_time"`
+	Plugin              string                 `json:"plugin"`
+	PluginSpecific      map[string]interface{} `json:"plugin_specific"`
+	Flags               *HandleFlags           `json:"flags"`
+	AgentCreated        int64                  `json:"agent-created"`
+	IceMode             string                 `json:"ice-mode"`
+	IceRole             string                 `json:"ice-role"`
+	Sdps                *HandleSdps            `json:"sdps"`
+	QueuedPackets       int                    `json:"queued-packets"`
+	Streams             []*HandleStream        `json:"streams"`
+}
+
+type HandleFlags struct {
+	GotOffer        bool `json:"got-offer"`
+	GotAnswer       bool `json:"got-answer"`
+	Negotiated      bool `json:"negotiated"`
+	ProcessingOffer bool `json:"processing-offer"`
+	Starting        bool `json:"starting"`
+	IceRestart      bool `json:"ice-restart"`
+	Ready           bool `json:"ready"`
+	Stopped         bool `json:"stopped"`
+	Alert           bool `json:"alert"`
+	Trickle         bool `json:"trickle"`
+	AllTrickles     bool `json:"all-trickles"`
+	ResendTrickles  bool `json:"resend-trickles"`
+	TrickleSynced   bool `json:"trickle-synced"`
+	DataChannels    bool `json:"data-channels"`
+	HasAudio        bool `json:"has-audio"`
+	HasVideo        bool `json:"has-video"`
+	Rfc4588Rtx      bool `json:"rfc4588-rtx"`
+	Cleaning        bool `json:"cleaning"`
+	E2ee            bool `json:"e2ee"`
+}
+
+type HandleSdps struct {
+	Profile string `json:"profile"`
+	Local   string `json:"local"`
+	Remote  string `json:"remote"`
+}
+
+// HandleStream is a stream of the PeerConnection of a handle. Ssrc and
+// RtcpStats are keyed by media, e.g. "audio", "video" or "video-sim1".
+type HandleStream struct {
+	ID         int                   `json:"id"`
+	Ready      int                   `json:"ready"`
+	Ssrc       map[string]uint32     `json:"ssrc"`
+	Codecs     *HandleCodecs         `json:"codecs"`
+	RtcpStats  map[string]*RtcpStats `json:"rtcp_stats"`
+	Components []*HandleComponent    `json:"components"`
+}
+
+type HandleCodecs struct {
+	AudioPT    int    `json:"audio-pt"`
+	AudioCodec string `json:"audio-codec"`
+	VideoPT    int    `json:"video-pt"`
+	VideoCodec string `json:"video-codec"`
+	VideoRtxPT int    `json:"video-rtx-pt"`
+}
+
+type RtcpStats struct {
+	Base                int `json:"base"`
+	Rtt                 int `json:"rtt"`
+	Lost                int `json:"lost"`
+	LostByRemote        int `json:"lost-by-remote"`
+	JitterLocal         int `json:"jitter-local"`
+	JitterRemote        int `json:"jitter-remote"`
+	InLinkQuality       int `json:"in-link-quality"`
+	InMediaLinkQuality  int `json:"in-media-link-quality"`
+	OutLinkQuality      int `json:"out-link-quality"`
+	OutMediaLinkQuality int `json:"out-media-link-quality"`
+}
+
+// HandleComponent is the ICE component of a stream. State is its ICE state
+// (e.g. "connected" or "failed"), and Gathered, Connected and Failed the
+// times it reached these states. SelectedPair is the candidate pair in use.
+type HandleComponent struct {
+	ID               int         `json:"id"`
+	State            string      `json:"state"`
+	Gathered         int64       `json:"gathered"`
+	Connected        int64       `json:"connected"`
+	Failed           int64       `json:"failed"`
+	LocalCandidates  []string    `json:"local-candidates"`
+	RemoteCandidates []string    `json:"remote-candidates"`
+	SelectedPair     string      `json:"selected-pair"`
+	Dtls             *HandleDtls `json:"dtls"`
+	InStats          *MediaStats `json:"in_stats"`
+	OutStats         *MediaStats `json:"out_stats"`
+}
+
+type HandleDtls struct {
+	Fingerprint           string `json:"fingerprint"`
+	RemoteFingerprint     string `json:"remote-fingerprint"`
+	RemoteFingerprintHash string `json:"remote-fingerprint-hash"`
+	DtlsRole              string `json:"dtls-role"`
+	DtlsState             string `json:"dtls-state"`
+	Retransmissions       int    `json:"retransmissions"`
+	Valid                 bool   `json:"valid"`
+	SrtpProfile           string `json:"srtp-profile"`
+	Ready                 bool   `json:"ready"`
+	HandshakeStarted      int64  `json:"handshake-started"`
+	Connected             int64  `json:"connected"`
+	SctpAssociation       bool   `json:"sctp-association"`
+}
+
+// MediaStats are the packets and bytes a component received (in) or sent
+// (out), by media.
+type MediaStats struct {
+	AudioPackets      uint64 `json:"audio_packets"`
+	AudioBytes        uint64 `json:"audio_bytes"`
+	AudioBytesLastSec uint64 `json:"audio_bytes_lastsec"`
+	AudioNacks        uint64 `json:"audio_nacks"`
+	VideoPackets      uint64 `json:"video_packets"`
+	VideoBytes        uint64 `json:"video_bytes"`
+	VideoBytesLastSec uint64 `json:"video_bytes_lastsec"`
+	VideoNacks        uint64 `json:"video_nacks"`
+	DataPackets       uint64 `json:"data_packets"`
+	DataBytes         uint64 `json:"data_bytes"`
 }
 
 var amResponseTypes = map[string]func() interface{}{
-	"success":        func() interface{} { return &SuccessAMResponse{} },
-	"error":          func() interface{} { return &ErrorAMResponse{} },
+	"success":     func() interface{} { return &SuccessAMResponse{} },
+	"error":       func() interface{} { return &ErrorAMResponse{} },
+	"server_info": func() interface{} { return &InfoResponse{} },
+	"pong":        func() interface{} { return &PongResponse{} },
+	"get_status":  func() interface{} { return &StatusResponse{} },
+
+	"set_session_timeout":    func() interface{} { return &SessionTimeoutResponse{} },
+	"set_log_level":          func() interface{} { return &LogLevelResponse{} },
+	"set_log_timestamps":     func() interface{} { return &LogTimestampsResponse{} },
+	"set_log_colors":         func() interface{} { return &LogColorsResponse{} },
+	"set_locking_debug":      func() interface{} { return &LockingDebugResponse{} },
+	"set_refcount_debug":     func() interface{} { return &RefcountDebugResponse{} },
+	"set_libnice_debug":      func() interface{} { return &LibniceDebugResponse{} },
+	"set_min_nack_queue":     func() interface{} { return &MinNackQueueResponse{} },
+	"set_no_media_timer":     func() interface{} { return &NoMediaTimerResponse{} },
+	"set_slowlink_threshold": func() interface{} { return &SlowlinkThresholdResponse{} },
+	"accept_new_sessions":    func() interface{} { return &AcceptNewSessionsResponse{} },
+
+	"add_token":      func() interface{} { return &TokenResponse{} },
+	"allow_token":    func() interface{} { return &TokenResponse{} },
+	"disallow_token": func() interface{} { return &TokenResponse{} },
 	"list_tokens":    func() interface{} { return &ListTokensResponse{} },
 	"list_sessions":  func() interface{} { return &ListSessionsResponse{} },
 	"message_plugin": func() interface{} { return &MessagePluginResponse{} },
 	"list_handles":   func() interface{} { return &ListHandlesResponse{} },
 	"handle_info":    func() interface{} { return &HandleInfoResponse{} },
+
+	"destroy_session": func() interface{} { return &DestroySessionResponse{} },
+	"detach_handle":   func() interface{} { return &DetachHandleResponse{} },
+	"hangup_webrtc":   func() interface{} { return &HangupWebRTCResponse{} },
+	"start_pcap":      func() interface{} { return &CaptureResponse{} },
+	"stop_pcap":       func() interface{} { return &CaptureResponse{} },
+	"start_text2pcap": func() interface{} { return &CaptureResponse{} },
+	"stop_text2pcap":  func() interface{} { return &CaptureResponse{} },
+	"message_handle":  func() interface{} { return &MessageHandleResponse{} },
 }
 
 func ParseAMResponse(r APIRequest, data []byte) (interface{}, error) {
@@ -185,17 +517,31 @@ func ParseAMResponse(r APIRequest, data []byte) (interface{}, error) {
 		return nil, fmt.Errorf("json.Unmarshal %s : %w", typeStr, err)
 	}
 
-	if mpRequest, ok := r.(*MessagePluginRequest); ok {
-		if pluginTypes, ok := plugins.ResponseTypeMap[mpRequest.Request.PluginName()]; ok {
-			actionName := mpRequest.Request.ActionName()
-			innerPayload := resp.(*MessagePluginResponse).Response
+	// Decode the response of a plugin to the type registered for the
+	// request, if any.
+	var pluginRequest plugins.PluginRequest
+	var innerPayload map[string]interface{}
+	switch r := r.(type) {
+	case *MessagePluginRequest:
+		if mpResp, ok := resp.(*MessagePluginResponse); ok {
+			pluginRequest, innerPayload = r.Request, mpResp.Response
+		}
+	case *MessageHandleRequest:
+		if mhResp, ok := resp.(*MessageHandleResponse); ok {
+			pluginRequest, innerPayload = r.Request, mhResp.Response
+		}
+	}
+
+	if pluginRequest != nil {
+		if pluginTypes, ok := plugins.ResponseTypeMap[pluginRequest.PluginName()]; ok {
+			actionName := pluginRequest.ActionName()
 			if _, ok := innerPayload["error"]; ok {
 				actionName = "error"
 			}
 			if typeFunc, ok = pluginTypes[actionName]; ok {
 				b, err := json.Marshal(innerPayload)
 				if err != nil {
-					return nil, fmt.Errorf("json.Marshal message_plugin response : %w", err)
+					return nil, fmt.Errorf("json.Marshal %s response : %w", typeStr, err)
 				}
 
 				resp = typeFunc()
@@ -0,0 +1,120 @@
+package janus
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGateway_RequestTimeout(t *testing.T) {
+	// a gateway which never answers
+	gateway := NewGateway(newMemTransport(func(req map[string]interface{}) []string {
+		return nil
+	}))
+	defer gateway.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := gateway.InfoContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	gateway.RequestTimeout = 10 * time.Millisecond
+	if _, err := gateway.Create(); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	if pending := gateway.InFlightTransactions(); pending != 0 {
+		t.Errorf("expected no pending transactions, found %d", pending)
+	}
+}
+
+func TestHandle_RequestTimeout(t *testing.T) {
+	// a gateway which never answers plugin messages
+	gateway := NewGateway(newMemTransport(func(req map[string]interface{}) []string {
+		tx := req["transaction"].(string)
+		switch req["janus"] {
+		case "create":
+			return []string{fmt.Sprintf(`{"janus":"success","transaction":"%s","data":{"id":1}}`, tx)}
+		case "attach":
+			return []string{fmt.Sprintf(`{"janus":"success","session_id":1,"transaction":"%s","data":{"id":2}}`, tx)}
+		}
+		return nil
+	}))
+	defer gateway.Close()
+
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle, err := session.Attach("janus.plugin.videoroom")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gateway.RequestTimeout = 10 * time.Millisecond
+	if _, err := handle.Request(map[string]interface{}{"request": "list"}); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	if pending := gateway.InFlightTransactions(); pending != 0 {
+		t.Errorf("expected no pending transactions, found %d", pending)
+	}
+}
+
+// stallingTransport stops sending once stalled, until the context of the
+// send is done.
+type stallingTransport struct {
+	*memTransport
+	stalled int32
+}
+
+func (t *stallingTransport) SendContext(ctx context.Context, data []byte) error {
+	if atomic.LoadInt32(&t.stalled) == 0 {
+		return t.Send(data)
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestHandle_MessageSendTimeout(t *testing.T) {
+	transport := &stallingTransport{memTransport: newMemTransport(func(req map[string]interface{}) []string {
+		tx := req["transaction"].(string)
+		switch req["janus"] {
+		case "create":
+			return []string{fmt.Sprintf(`{"janus":"success","transaction":"%s","data":{"id":1}}`, tx)}
+		case "attach":
+			return []string{fmt.Sprintf(`{"janus":"success","session_id":1,"transaction":"%s","data":{"id":2}}`, tx)}
+		}
+		return nil
+	})}
+	gateway := NewGateway(transport)
+	defer gateway.Close()
+
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle, err := session.Attach("janus.plugin.videoroom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&transport.stalled, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := handle.MessageAsyncContext(ctx, map[string]interface{}{"request": "configure"}, nil); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	gateway.RequestTimeout = 10 * time.Millisecond
+	if _, err := handle.Message(map[string]interface{}{"request": "configure"}, nil); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	if pending := gateway.InFlightTransactions(); pending != 0 {
+		t.Errorf("expected no pending transactions, found %d", pending)
+	}
+}
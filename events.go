@@ -0,0 +1,155 @@
+package janus
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultEventBufferSize is the capacity of the Events channels of sessions
+// and handles, unless set by Gateway.EventBufferSize.
+const DefaultEventBufferSize = 32
+
+// DefaultEventOverflow is the overflow policy of the Events channels of
+// sessions and handles, unless set by Gateway.EventOverflow.
+const DefaultEventOverflow = OverflowDropOldest
+
+// OverflowPolicy decides what happens to an event delivered to a full
+// Events channel. Dropped events are counted by Session.Dropped and
+// Handle.Dropped.
+type OverflowPolicy int
+
+const (
+	// OverflowDropOldest discards the oldest event queued in the channel to
+	// make room for the new one. This is the zero value.
+	OverflowDropOldest OverflowPolicy = iota
+
+	// OverflowDropNewest discards the event being delivered.
+	OverflowDropNewest
+
+	// OverflowBlock never discards events: those which don't fit wait in a
+	// backlog of the Events channel until the consumer makes room, without
+	// holding up the other sessions and handles. The backlog grows as long
+	// as the consumer falls behind.
+	OverflowBlock
+)
+
+// eventQueue delivers events in order to an Events channel, applying the
+// overflow policy when the channel is full.
+type eventQueue struct {
+	ch      chan interface{}
+	policy  OverflowPolicy
+	closed  chan struct{}
+	dropped uint64
+	mu      sync.Mutex
+
+	// backlog holds the events waiting for room in ch with OverflowBlock,
+	// which are delivered by a goroutine while draining is set.
+	backlog  []interface{}
+	draining bool
+}
+
+func (gateway *Gateway) newEventQueue() *eventQueue {
+	size := gateway.EventBufferSize
+	if size <= 0 {
+		size = DefaultEventBufferSize
+	}
+
+	return &eventQueue{
+		ch:     make(chan interface{}, size),
+		policy: gateway.EventOverflow,
+		closed: gateway.closed,
+	}
+}
+
+func (q *eventQueue) push(msg interface{}) {
+	if q.policy == OverflowBlock {
+		q.enqueue(msg)
+		return
+	}
+
+	select {
+	case q.ch <- msg:
+		return
+	default:
+	}
+
+	switch q.policy {
+	case OverflowDropNewest:
+		atomic.AddUint64(&q.dropped, 1)
+	default:
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		for {
+			select {
+			case q.ch <- msg:
+				return
+			default:
+			}
+			select {
+			case <-q.ch:
+				atomic.AddUint64(&q.dropped, 1)
+			default:
+			}
+		}
+	}
+}
+
+// enqueue delivers msg, or appends it to the backlog if ch is full or
+// earlier events are still waiting.
+func (q *eventQueue) enqueue(msg interface{}) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.draining {
+		select {
+		case q.ch <- msg:
+			return
+		default:
+		}
+		q.draining = true
+		go q.drain()
+	}
+	q.backlog = append(q.backlog, msg)
+}
+
+// drain delivers the backlog in order, until it is empty or the Gateway is
+// closed.
+func (q *eventQueue) drain() {
+	for {
+		q.mu.Lock()
+		if len(q.backlog) == 0 {
+			q.draining = false
+			q.mu.Unlock()
+			return
+		}
+		msg := q.backlog[0]
+		q.backlog[0] = nil
+		q.backlog = q.backlog[1:]
+		q.mu.Unlock()
+
+		select {
+		case q.ch <- msg:
+		case <-q.closed:
+			return
+		}
+	}
+}
+
+func (q *eventQueue) droppedCount() uint64 {
+	if q == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&q.dropped)
+}
+
+// Dropped returns the number of events discarded because Session.Events was
+// full.
+func (session *Session) Dropped() uint64 {
+	return session.events.droppedCount()
+}
+
+// Dropped returns the number of events discarded because Handle.Events was
+// full.
+func (handle *Handle) Dropped() uint64 {
+	return handle.events.droppedCount()
+}
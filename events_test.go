@@ -0,0 +1,165 @@
+package janus
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestHandle(t *testing.T) (*memTransport, *Gateway, *Session, *Handle) {
+	transport := newMemTransport(func(req map[string]interface{}) []string {
+		tx := req["transaction"]
+		switch req["janus"] {
+		case "create":
+			return []string{fmt.Sprintf(`{"janus":"success","transaction":"%s","data":{"id":1}}`, tx)}
+		case "attach":
+			return []string{fmt.Sprintf(`{"janus":"success","session_id":1,"transaction":"%s","data":{"id":2}}`, tx)}
+		}
+		t.Errorf("unexpected request %v", req["janus"])
+		return nil
+	})
+
+	gateway := NewGateway(transport)
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle, err := session.Attach("janus.plugin.echotest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transport, gateway, session, handle
+}
+
+func nextEvent(t *testing.T, events chan interface{}) interface{} {
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered")
+	}
+	return nil
+}
+
+func TestGateway_HandleEvents(t *testing.T) {
+	transport, gateway, session, handle := newTestHandle(t)
+	defer gateway.Close()
+
+	transport.in <- []byte(`{"janus":"hangup","session_id":1,"sender":2,"reason":"DTLS alert"}`)
+	if ev, ok := nextEvent(t, handle.Events).(*HangupMsg); !ok || ev.Reason != "DTLS alert" {
+		t.Errorf("expected HangupMsg, got %v", ev)
+	}
+
+	transport.in <- []byte(`{"janus":"detached","session_id":1,"sender":2}`)
+	if ev, ok := nextEvent(t, handle.Events).(*DetachedMsg); !ok || ev.Handle != handle.ID {
+		t.Errorf("expected DetachedMsg, got %v", ev)
+	}
+
+	session.Lock()
+	_, ok := session.Handles[handle.ID]
+	session.Unlock()
+	if ok {
+		t.Error("detached handle should have been removed")
+	}
+}
+
+func TestGateway_SessionEvents(t *testing.T) {
+	transport, gateway, session, _ := newTestHandle(t)
+	defer gateway.Close()
+
+	transport.in <- []byte(`{"janus":"timeout","session_id":1}`)
+	if ev, ok := nextEvent(t, session.Events).(*TimeoutMsg); !ok || ev.Session != session.ID {
+		t.Errorf("expected TimeoutMsg, got %v", ev)
+	}
+
+	gateway.Lock()
+	_, ok := gateway.Sessions[session.ID]
+	gateway.Unlock()
+	if ok {
+		t.Error("timed out session should have been removed")
+	}
+}
+
+func TestEventQueue_Overflow(t *testing.T) {
+	for policy, expected := range map[OverflowPolicy][]int{
+		OverflowDropOldest: {3, 4},
+		OverflowDropNewest: {0, 1},
+	} {
+		gateway := &Gateway{EventBufferSize: 2, EventOverflow: policy}
+		q := gateway.newEventQueue()
+		for i := 0; i < 5; i++ {
+			q.push(i)
+		}
+
+		if q.droppedCount() != 3 {
+			t.Errorf("policy %d: expected 3 dropped events, got %d", policy, q.droppedCount())
+		}
+		for _, n := range expected {
+			if ev := <-q.ch; ev != n {
+				t.Errorf("policy %d: expected event %d, got %v", policy, n, ev)
+			}
+		}
+	}
+}
+
+func TestEventQueue_DefaultOverflow(t *testing.T) {
+	gateway := &Gateway{EventBufferSize: 1}
+	q := gateway.newEventQueue()
+	if q.policy != DefaultEventOverflow {
+		t.Fatalf("expected policy %d, got %d", DefaultEventOverflow, q.policy)
+	}
+
+	q.push(0)
+	q.push(1)
+	if q.droppedCount() != 1 {
+		t.Errorf("expected 1 dropped event, got %d", q.droppedCount())
+	}
+	if ev := <-q.ch; ev != 1 {
+		t.Errorf("expected event 1, got %v", ev)
+	}
+}
+
+func TestGateway_EventOrder(t *testing.T) {
+	transport, gateway, _, handle := newTestHandle(t)
+	defer gateway.Close()
+
+	for i := 0; i < 5; i++ {
+		transport.in <- []byte(fmt.Sprintf(`{"janus":"event","session_id":1,"sender":2,"plugindata":{"plugin":"janus.plugin.echotest","data":{"n":%d}}}`, i))
+	}
+	for i := 0; i < 5; i++ {
+		ev := nextEvent(t, handle.Events).(*EventMsg)
+		if n := ev.Plugindata.Data["n"]; n != float64(i) {
+			t.Errorf("expected event %d, got %v", i, n)
+		}
+	}
+	if handle.Dropped() != 0 {
+		t.Errorf("expected no dropped events, got %d", handle.Dropped())
+	}
+}
+
+func TestGateway_EventOverflowBlock(t *testing.T) {
+	transport, gateway, session, handle := newTestHandle(t)
+	defer gateway.Close()
+	handle.events.policy = OverflowBlock
+
+	n := 2 * DefaultEventBufferSize
+	for i := 0; i < n; i++ {
+		transport.in <- []byte(fmt.Sprintf(`{"janus":"event","session_id":1,"sender":2,"plugindata":{"plugin":"janus.plugin.echotest","data":{"n":%d}}}`, i))
+	}
+
+	// the handle nobody reads does not hold up the rest of the gateway
+	transport.in <- []byte(`{"janus":"timeout","session_id":1}`)
+	if _, ok := nextEvent(t, session.Events).(*TimeoutMsg); !ok {
+		t.Error("expected a TimeoutMsg")
+	}
+
+	for i := 0; i < n; i++ {
+		ev := nextEvent(t, handle.Events).(*EventMsg)
+		if v := ev.Plugindata.Data["n"]; v != float64(i) {
+			t.Errorf("expected event %d, got %v", i, v)
+		}
+	}
+	if handle.Dropped() != 0 {
+		t.Errorf("expected no dropped events, got %d", handle.Dropped())
+	}
+}
@@ -0,0 +1,358 @@
+package janus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// HttpTransport is a Transport speaking the Janus REST API. Every request is POSTed to the
+// endpoint of the session or handle it addresses, and the response body is
+// queued as an incoming frame. Asynchronous events are fetched by a GET
+// long-poll loop which is started for every session created (or claimed)
+// through the connection, and stopped when that session is destroyed.
+type HttpTransport struct {
+	// Timeout bounds the POST requests sent without a deadline, e.g. with
+	// no RequestTimeout set on the Gateway. Defaults to 10 seconds.
+	Timeout time.Duration
+
+	url    string
+	client *http.Client
+
+	frames chan []byte
+	errors chan error
+	closed chan struct{}
+
+	sync.Mutex
+	polls     map[uint64]context.CancelFunc
+	closeOnce sync.Once
+}
+
+// httpRequest holds the fields of an outgoing frame needed to route it.
+type httpRequest struct {
+	Type      string `json:"janus"`
+	Session   uint64 `json:"session_id"`
+	Handle    uint64 `json:"handle_id"`
+	ApiSecret string `json:"apisecret"`
+	Token     string `json:"token"`
+}
+
+// httpResponse holds the fields of an incoming frame needed to manage the
+// long-poll loops.
+type httpResponse struct {
+	Type    string      `json:"janus"`
+	Session uint64      `json:"session_id"`
+	Data    SuccessData `json:"data"`
+	Err     ErrorData   `json:"error"`
+}
+
+// Janus error code for requests addressing an unknown session.
+const errSessionNotFound = 458
+
+var errConnClosed = errors.New("janus http: connection closed")
+
+// A failed long-poll is retried after a backoff doubling from pollBackoff
+// up to pollMaxBackoff. The connection is considered lost after pollRetries
+// consecutive failures.
+const (
+	pollBackoff    = 100 * time.Millisecond
+	pollMaxBackoff = 5 * time.Second
+	pollRetries    = 8
+)
+
+// httpStatusError is returned for HTTP responses with an error status.
+type httpStatusError struct {
+	Code   int
+	Status string
+}
+
+func (err *httpStatusError) Error() string {
+	return "janus http: " + err.Status
+}
+
+func NewHttpTransport(url string) *HttpTransport {
+	c := new(HttpTransport)
+	c.Timeout = 10 * time.Second
+	c.url = strings.TrimSuffix(url, "/")
+	c.client = &http.Client{
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{
+				Timeout: 5 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+	}
+	c.frames = make(chan []byte, 32)
+	c.errors = make(chan error, 1)
+	c.closed = make(chan struct{})
+	c.polls = make(map[uint64]context.CancelFunc)
+	return c
+}
+
+func (c *HttpTransport) Send(data []byte) error {
+	return c.SendContext(context.Background(), data)
+}
+
+// SendContext is like Send, but gives up waiting for the response of the
+// server when ctx is done.
+func (c *HttpTransport) SendContext(ctx context.Context, data []byte) error {
+	var req httpRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return err
+	}
+
+	endpoint := c.url
+	if req.Session != 0 {
+		endpoint = fmt.Sprintf("%s/%d", endpoint, req.Session)
+		if req.Handle != 0 {
+			endpoint = fmt.Sprintf("%s/%d", endpoint, req.Handle)
+		}
+	}
+
+	body, err := c.post(ctx, endpoint, data)
+	if err != nil {
+		return err
+	}
+
+	var resp httpResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	switch req.Type {
+	case "create":
+		if resp.Type == "success" {
+			c.startPoll(resp.Data.ID, &req)
+		}
+	case "claim":
+		if resp.Type == "success" {
+			c.startPoll(req.Session, &req)
+		}
+	case "destroy":
+		if resp.Type != "error" {
+			c.stopPoll(req.Session)
+		}
+	}
+
+	return c.queue(body)
+}
+
+func (c *HttpTransport) Receive() ([]byte, error) {
+	select {
+	case data := <-c.frames:
+		return data, nil
+	case err := <-c.errors:
+		return nil, err
+	case <-c.closed:
+		return nil, errConnClosed
+	}
+}
+
+func (c *HttpTransport) Ping() error {
+	data, err := json.Marshal(map[string]interface{}{
+		"janus":       "ping",
+		"transaction": xid.New().String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	body, err := c.post(context.Background(), c.url, data)
+	if err != nil {
+		return err
+	}
+
+	var resp httpResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+	if resp.Type != "pong" {
+		return unexpected("ping")
+	}
+	return nil
+}
+
+func (c *HttpTransport) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.Lock()
+		for id, cancel := range c.polls {
+			cancel()
+			delete(c.polls, id)
+		}
+		c.Unlock()
+	})
+	return nil
+}
+
+// post sends a POST request, bounded by Timeout if ctx has no deadline.
+func (c *HttpTransport) post(ctx context.Context, endpoint string, data []byte) ([]byte, error) {
+	if _, ok := ctx.Deadline(); !ok && c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	return c.do(ctx, http.MethodPost, endpoint, data)
+}
+
+func (c *HttpTransport) do(ctx context.Context, method, endpoint string, data []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, &httpStatusError{Code: resp.StatusCode, Status: resp.Status}
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (c *HttpTransport) queue(data []byte) error {
+	select {
+	case c.frames <- data:
+		return nil
+	case <-c.closed:
+		return errConnClosed
+	}
+}
+
+func (c *HttpTransport) fail(err error) {
+	select {
+	case c.errors <- err:
+	default:
+	}
+}
+
+func (c *HttpTransport) startPoll(sessionID uint64, req *httpRequest) {
+	query := url.Values{}
+	if len(req.ApiSecret) > 0 {
+		query.Set("apisecret", req.ApiSecret)
+	} else if len(req.Token) > 0 {
+		query.Set("token", req.Token)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c.Lock()
+	if _, ok := c.polls[sessionID]; ok {
+		c.Unlock()
+		cancel()
+		return
+	}
+	c.polls[sessionID] = cancel
+	c.Unlock()
+
+	go c.poll(ctx, sessionID, query)
+}
+
+func (c *HttpTransport) stopPoll(sessionID uint64) {
+	c.Lock()
+	cancel, ok := c.polls[sessionID]
+	delete(c.polls, sessionID)
+	c.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+func (c *HttpTransport) poll(ctx context.Context, sessionID uint64, query url.Values) {
+	defer c.stopPoll(sessionID)
+
+	failures := 0
+	for {
+		query.Set("rid", fmt.Sprint(time.Now().UnixNano()/int64(time.Millisecond)))
+		endpoint := fmt.Sprintf("%s/%d?%s", c.url, sessionID, query.Encode())
+
+		var resp httpResponse
+		body, err := c.do(ctx, http.MethodGet, endpoint, nil)
+		if err == nil {
+			err = json.Unmarshal(body, &resp)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// The session is gone, or the server stayed unreachable: give up.
+			// Other failures are retried, as a transient network error
+			// should not lose the connection.
+			if statusErr, ok := err.(*httpStatusError); ok && statusErr.Code == http.StatusNotFound {
+				c.fail(err)
+				return
+			}
+			failures++
+			if failures > pollRetries {
+				c.fail(err)
+				return
+			}
+			if !sleep(ctx, pollDelay(failures)) {
+				return
+			}
+			continue
+		}
+		failures = 0
+
+		switch resp.Type {
+		case "keepalive":
+			// long-poll timed out without events
+			continue
+		case "error":
+			if resp.Err.Code == errSessionNotFound {
+				// Janus dropped the session: report it as timed out, so
+				// that the Gateway removes it and notifies its Events.
+				c.queue([]byte(fmt.Sprintf(`{"janus":"timeout","session_id":%d}`, sessionID)))
+				return
+			}
+		}
+
+		if c.queue(body) != nil {
+			return
+		}
+	}
+}
+
+// pollDelay returns the backoff before retrying a long-poll after failures
+// consecutive failures.
+func pollDelay(failures int) time.Duration {
+	delay := pollBackoff
+	for i := 1; i < failures && delay < pollMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > pollMaxBackoff {
+		delay = pollMaxBackoff
+	}
+	return delay
+}
+
+// sleep waits for d, and reports false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
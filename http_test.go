@@ -0,0 +1,228 @@
+package janus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_ConnectHTTP(t *testing.T) {
+	events := make(chan string, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			select {
+			case ev := <-events:
+				fmt.Fprint(w, ev)
+			case <-time.After(100 * time.Millisecond):
+				fmt.Fprint(w, `{"janus":"keepalive"}`)
+			}
+			return
+		}
+
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		tx := req["transaction"]
+		switch req["janus"] {
+		case "create":
+			fmt.Fprintf(w, `{"janus":"success","transaction":"%s","data":{"id":1}}`, tx)
+		case "attach":
+			if r.URL.Path != "/janus/1" {
+				t.Errorf("attach sent to %s", r.URL.Path)
+			}
+			fmt.Fprintf(w, `{"janus":"success","session_id":1,"transaction":"%s","data":{"id":2}}`, tx)
+		default:
+			t.Errorf("unexpected request %v", req["janus"])
+		}
+	}))
+	defer srv.Close()
+
+	client, err := Connect(srv.URL + "/janus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	session, err := client.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle, err := session.Attach("janus.plugin.echotest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events <- `{"janus":"event","session_id":1,"sender":2,"plugindata":{"plugin":"janus.plugin.echotest","data":{"echotest":"event"}}}`
+
+	select {
+	case msg := <-handle.Events:
+		ev, ok := msg.(*EventMsg)
+		if !ok {
+			t.Fatalf("wrong type: EventMsg != %T", msg)
+		}
+		if !strings.HasSuffix(ev.Plugindata.Plugin, "echotest") {
+			t.Errorf("unexpected plugin %s", ev.Plugindata.Plugin)
+		}
+	case <-time.After(time.Second):
+		t.Error("event not delivered")
+	}
+}
+
+func TestHttpTransport_SendTimeout(t *testing.T) {
+	// a server which never answers POSTs
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client, err := ConnectHTTP(srv.URL + "/janus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	client.RequestTimeout = 50 * time.Millisecond
+	if _, err := client.Create(); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// without RequestTimeout, the POST is bounded by the transport
+	client.RequestTimeout = 0
+	client.getTransport().(*HttpTransport).Timeout = 50 * time.Millisecond
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Create()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected an error from the hung POST")
+		}
+	case <-time.After(time.Second):
+		t.Error("POST not given up after the transport timeout")
+	}
+}
+
+func TestHttpTransport_PollRetry(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			polls++
+			n := polls
+			mu.Unlock()
+			switch {
+			case n <= 2:
+				// transient failures
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			case n == 3:
+				fmt.Fprint(w, `{"janus":"event","session_id":1,"sender":2,"plugindata":{"plugin":"janus.plugin.echotest","data":{"echotest":"event"}}}`)
+			default:
+				time.Sleep(100 * time.Millisecond)
+				fmt.Fprint(w, `{"janus":"keepalive"}`)
+			}
+			return
+		}
+
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		tx := req["transaction"]
+		switch req["janus"] {
+		case "create":
+			fmt.Fprintf(w, `{"janus":"success","transaction":"%s","data":{"id":1}}`, tx)
+		case "attach":
+			fmt.Fprintf(w, `{"janus":"success","session_id":1,"transaction":"%s","data":{"id":2}}`, tx)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := ConnectHTTP(srv.URL + "/janus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	session, err := client.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle, err := session.Attach("janus.plugin.echotest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-handle.Events:
+		if _, ok := msg.(*EventMsg); !ok {
+			t.Errorf("wrong type: EventMsg != %T", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event not delivered after the failed long-polls")
+	}
+
+	// the gateway survived the failures
+	if _, err := session.Attach("janus.plugin.echotest"); err != nil {
+		t.Errorf("expected the gateway to keep working, got %v", err)
+	}
+}
+
+func TestHttpTransport_PollSessionNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `{"janus":"error","session_id":1,"error":{"code":458,"reason":"No such session 1"}}`)
+			return
+		}
+
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+		if req["janus"] == "create" {
+			fmt.Fprintf(w, `{"janus":"success","transaction":"%s","data":{"id":1}}`, req["transaction"])
+		}
+	}))
+	defer srv.Close()
+
+	client, err := ConnectHTTP(srv.URL + "/janus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	session, err := client.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-session.Events:
+		if _, ok := msg.(*TimeoutMsg); !ok {
+			t.Errorf("wrong type: TimeoutMsg != %T", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("lost session not reported")
+	}
+
+	client.Lock()
+	_, ok := client.Sessions[session.ID]
+	client.Unlock()
+	if ok {
+		t.Errorf("session %d should have been removed", session.ID)
+	}
+}
@@ -3,19 +3,23 @@
 package janus
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"os"
+	"strings"
 	"sync"
 	"time"
-
-	"github.com/gorilla/websocket"
-	"github.com/rs/xid"
 )
 
 var debug = false
 
+// ErrClosed is returned by requests which were still waiting for a response
+// when the Gateway was closed.
+var ErrClosed = errors.New("janus: gateway closed")
+
 func unexpected(request string) error {
 	return fmt.Errorf("Unexpected response received to '%s' request", request)
 }
@@ -23,7 +27,8 @@ func unexpected(request string) error {
 func newRequest(method string) (map[string]interface{}, chan interface{}) {
 	req := make(map[string]interface{}, 8)
 	req["janus"] = method
-	return req, make(chan interface{})
+	// room for both the ack and the event of a message
+	return req, make(chan interface{}, 2)
 }
 
 // Gateway represents a connection to an instance of the Janus Gateway.
@@ -37,47 +42,129 @@ type Gateway struct {
 	// See https://janus.conf.meetecho.com/docs/auth.html#token
 	Token string
 
+	// Reconnect enables re-dialing the gateway with Dial when the connection
+	// is lost. Sessions are reclaimed on the new connection.
+	Reconnect *ReconnectPolicy
+
+	// Dial creates a new transport to the gateway. It is set by Connect and
+	// needs to be set by the caller for gateways created with NewGateway.
+	Dial func() (Transport, error)
+
+	// KeepAliveInterval enables sending keepalive requests for every session
+	// created through the Gateway. It should be well below the session_timeout
+	// configured in Janus. Failures are delivered on Session.Events as a
+	// *KeepAliveError.
+	KeepAliveInterval time.Duration
+
+	// KeepAliveJitter randomizes each keepalive interval by up to +/- this
+	// duration, to spread the keepalives of many sessions. It is capped to
+	// half of KeepAliveInterval.
+	KeepAliveJitter time.Duration
+
+	// RequestTimeout limits how long requests wait for their response, unless
+	// called with a context which already has a deadline. 0 waits forever.
+	RequestTimeout time.Duration
+
+	// EventBufferSize is the capacity of the Events channels of new sessions
+	// and handles, DefaultEventBufferSize if unset.
+	EventBufferSize int
+
+	// EventOverflow decides what happens to events delivered to a full Events
+	// channel of new sessions and handles, DefaultEventOverflow if unset:
+	// the oldest events are silently discarded, and only counted by
+	// Session.Dropped and Handle.Dropped. OverflowBlock keeps them instead,
+	// at the cost of memory while the consumer falls behind.
+	EventOverflow OverflowPolicy
+
+	// TransactionTimeout is how long a request is tracked while waiting for
+	// its final response, before it is considered abandoned and expired.
+	// 0 disables expiry.
+	TransactionTimeout time.Duration
+
 	// Access to the Sessions map should be synchronized with the Gateway.Lock()
 	// and Gateway.Unlock() methods provided by the embedded sync.Mutex.
 	sync.Mutex
 
-	conn             *websocket.Conn
-	transactions     map[xid.ID]chan interface{}
-	transactionsUsed map[xid.ID]bool
+	transport        Transport
+	transportGen     uint64
+	transportMu      sync.RWMutex
+	transactions     *transactionManager
 	errors           chan error
+	reclaims         chan *ReclaimResult
 	shutdown         bool
+	closed           chan struct{}
+	closeOnce        sync.Once
 	sendChan         chan []byte
-	writeMu          sync.Mutex
 }
 
-// Connect initiates a webscoket connection with the Janus Gateway
-func Connect(wsURL string) (*Gateway, error) {
-	websocket.DefaultDialer.Subprotocols = []string{"janus-protocol"}
-
-	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+// Connect initiates a connection with the Janus Gateway.
+// URLs with a ws:// or wss:// scheme are dialed as a websocket,
+// http:// and https:// URLs use the REST API (e.g. http://localhost:8088/janus)
+// and unix:// URLs use the Unix Sockets interface (see DialUnix).
+func Connect(url string) (*Gateway, error) {
+	dial := func() (Transport, error) {
+		if strings.HasPrefix(url, "http") {
+			return NewHttpTransport(url), nil
+		} else if strings.HasPrefix(url, "unix") {
+			return DialUnix(url)
+		}
+		return DialWebsocket(url)
+	}
 
+	transport, err := dial()
 	if err != nil {
 		return nil, err
 	}
 
+	gateway := NewGateway(transport)
+	gateway.Dial = dial
+	return gateway, nil
+}
+
+// ConnectHTTP creates a Gateway using the REST API of the Janus Gateway.
+// Requests are sent as POSTs, while events are fetched by a long-poll loop
+// for each session created through the Gateway.
+func ConnectHTTP(url string) (*Gateway, error) {
+	gateway := NewGateway(NewHttpTransport(url))
+	gateway.Dial = func() (Transport, error) {
+		return NewHttpTransport(url), nil
+	}
+	return gateway, nil
+}
+
+// NewGateway creates a Gateway exchanging messages with the Janus Gateway
+// over the given transport.
+func NewGateway(transport Transport) *Gateway {
 	gateway := new(Gateway)
-	gateway.conn = conn
-	gateway.transactions = make(map[xid.ID]chan interface{})
-	gateway.transactionsUsed = make(map[xid.ID]bool)
+	gateway.transport = transport
+	gateway.transactions = newTransactionManager()
+	gateway.TransactionTimeout = DefaultTransactionTimeout
 	gateway.Sessions = make(map[uint64]*Session)
 	gateway.sendChan = make(chan []byte, 100)
 	gateway.errors = make(chan error)
+	gateway.reclaims = make(chan *ReclaimResult, 16)
 	gateway.shutdown = false
+	gateway.closed = make(chan struct{})
 
 	go gateway.ping()
 	go gateway.recv()
-	return gateway, nil
+	go gateway.expireTransactions()
+	return gateway
 }
 
 // Close closes the underlying connection to the Gateway.
 func (gateway *Gateway) Close() error {
 	gateway.shutdown = true
-	return gateway.conn.Close()
+	gateway.closeOnce.Do(func() {
+		close(gateway.closed)
+	})
+	return gateway.getTransport().Close()
+}
+
+func (gateway *Gateway) getTransport() Transport {
+	gateway.transportMu.RLock()
+	defer gateway.transportMu.RUnlock()
+	return gateway.transport
 }
 
 // GetErrChan returns a channels through which the caller can check and react to connectivity errors
@@ -85,14 +172,15 @@ func (gateway *Gateway) GetErrChan() chan error {
 	return gateway.errors
 }
 
-func (gateway *Gateway) send(msg map[string]interface{}, transaction chan interface{}) {
-	guid := xid.New()
+// sendContext sends msg as a new transaction, giving up sending when ctx is
+// done for transports implementing ContextSender.
+func (gateway *Gateway) sendContext(ctx context.Context, msg map[string]interface{}, transaction chan interface{}) (*transaction, error) {
+	gateway.transportMu.RLock()
+	transport, gen := gateway.transport, gateway.transportGen
+	gateway.transportMu.RUnlock()
 
-	msg["transaction"] = guid.String()
-	gateway.Lock()
-	gateway.transactions[guid] = transaction
-	gateway.transactionsUsed[guid] = false
-	gateway.Unlock()
+	tx := gateway.transactions.add(msg["janus"].(string), transaction, gateway.TransactionTimeout, gen)
+	msg["transaction"] = tx.id.String()
 
 	if len(gateway.ApiSecret) > 0 {
 		msg["apisecret"] = gateway.ApiSecret
@@ -102,21 +190,28 @@ func (gateway *Gateway) send(msg map[string]interface{}, transaction chan interf
 
 	data, err := json.Marshal(msg)
 	if err != nil {
+		gateway.transactions.remove(tx.id)
 		select {
 		case gateway.errors <- err:
 		default:
 			fmt.Fprintf(os.Stderr, "json.Marshal: %s\n", err)
 		}
-		return
+		return nil, err
 	}
 
-	gateway.writeMu.Lock()
-	err = gateway.conn.WriteMessage(websocket.TextMessage, data)
-	gateway.writeMu.Unlock()
+	if sender, ok := transport.(ContextSender); ok {
+		err = sender.SendContext(ctx, data)
+	} else {
+		err = transport.Send(data)
+	}
 
 	if err != nil {
+		gateway.transactions.remove(tx.id)
 		if gateway.shutdown {
-			return
+			return nil, ErrClosed
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
 		select {
 		case gateway.errors <- err:
@@ -124,12 +219,48 @@ func (gateway *Gateway) send(msg map[string]interface{}, transaction chan interf
 			fmt.Fprintf(os.Stderr, "conn.Write: %s\n", err)
 		}
 
-		return
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// withTimeout applies the RequestTimeout of the Gateway to ctx, unless ctx
+// already has a deadline.
+func (gateway *Gateway) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || gateway.RequestTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, gateway.RequestTimeout)
+}
+
+// wait blocks until a response to the transaction is received. The
+// transaction is removed if ctx is done first.
+func (gateway *Gateway) wait(ctx context.Context, tx *transaction) (interface{}, error) {
+	select {
+	case msg := <-tx.ch:
+		return msg, nil
+	case <-tx.expired:
+		return nil, tx.failure()
+	case <-ctx.Done():
+		gateway.transactions.remove(tx.id)
+		return nil, ctx.Err()
+	case <-gateway.closed:
+		gateway.transactions.remove(tx.id)
+		return nil, ErrClosed
 	}
 }
 
-func passMsg(ch chan interface{}, msg interface{}) {
-	ch <- msg
+// request sends msg and waits for the response to it.
+func (gateway *Gateway) request(ctx context.Context, msg map[string]interface{}, transaction chan interface{}) (interface{}, error) {
+	ctx, cancel := gateway.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := gateway.sendContext(ctx, msg, transaction)
+	if err != nil {
+		return nil, err
+	}
+	return gateway.wait(ctx, tx)
 }
 
 func (gateway *Gateway) ping() {
@@ -141,13 +272,17 @@ func (gateway *Gateway) ping() {
 			if gateway.shutdown {
 				return
 			}
-			err := gateway.conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(20*time.Second))
+			err := gateway.getTransport().Ping()
 			if err != nil {
 				select {
 				case gateway.errors <- err:
 				default:
 					fmt.Fprintf(os.Stderr, "janus ping error: %v\n", err)
 				}
+				if gateway.Reconnect != nil {
+					// recv notices the broken connection and reconnects
+					continue
+				}
 				return
 			}
 		}
@@ -162,11 +297,22 @@ func (gateway *Gateway) recv() {
 
 	for {
 		// Read message from Gateway
-		_, data, err := gateway.conn.ReadMessage()
+		data, err := gateway.getTransport().Receive()
 		if err != nil {
 			if gateway.shutdown {
 				return
 			}
+			if gateway.Reconnect != nil {
+				select {
+				case gateway.errors <- err:
+				default:
+					fmt.Fprintf(os.Stderr, "conn.Read: %s\n", err)
+				}
+				if gateway.reconnect() {
+					continue
+				}
+				return
+			}
 			if operr, ok := err.(*net.OpError); ok {
 				if !operr.Temporary() {
 					return
@@ -189,58 +335,62 @@ func (gateway *Gateway) recv() {
 			continue
 		}
 
-		var transactionUsed bool
+		var tx *transaction
 		if base.ID != "" {
-			id, _ := xid.FromString(base.ID)
-			gateway.Lock()
-			transactionUsed = gateway.transactionsUsed[id]
-			gateway.Unlock()
-
+			tx = gateway.transactions.resolve(base.ID, msg)
 		}
 
 		// Pass message on from here
-		if base.PluginData.Plugin != "" || transactionUsed {
-			// Is this a Handle event?
-			if base.Handle == 0 {
-				// Error()
-			} else {
-				// Lookup Session
-				gateway.Lock()
-				session := gateway.Sessions[base.Session]
-				gateway.Unlock()
-				if session == nil {
-					fmt.Fprintf(os.Stderr,"Unable to deliver message. Session gone?\n")
-					continue
-				}
+		if tx == nil {
+			// Lookup Session
+			gateway.Lock()
+			session := gateway.Sessions[base.Session]
+			gateway.Unlock()
+			if session == nil {
+				fmt.Fprintf(os.Stderr, "Unable to deliver message. Session gone?\n")
+				continue
+			}
 
-				// Lookup Handle
-				session.Lock()
-				handle := session.Handles[base.Handle]
-				session.Unlock()
-				if handle == nil {
-					fmt.Fprintf(os.Stderr,"Unable to deliver message. Handle gone?\n")
-					continue
+			// Is this a Session event?
+			if base.Handle == 0 {
+				if _, ok := msg.(*TimeoutMsg); ok {
+					// Remove the expired session from the gateway
+					gateway.Lock()
+					delete(gateway.Sessions, session.ID)
+					gateway.Unlock()
+					session.stopKeepAlive()
 				}
 
 				// Pass msg
-				go passMsg(handle.Events, msg)
+				session.events.push(msg)
+				continue
 			}
-		} else {
-			id, _ := xid.FromString(base.ID)
-			// Lookup Transaction
-			gateway.Lock()
-			transaction := gateway.transactions[id]
-			switch msg.(type) {
-			case *EventMsg:
-				gateway.transactionsUsed[id] = true
+
+			// Lookup Handle
+			session.Lock()
+			handle := session.Handles[base.Handle]
+			if _, ok := msg.(*DetachedMsg); ok {
+				// Remove the detached handle from the session
+				delete(session.Handles, base.Handle)
 			}
-			gateway.Unlock()
-			if transaction == nil {
-				// Error()
+			session.Unlock()
+			if handle == nil {
+				fmt.Fprintf(os.Stderr, "Unable to deliver message. Handle gone?\n")
+				continue
 			}
 
 			// Pass msg
-			go passMsg(transaction, msg)
+			handle.events.push(msg)
+		} else {
+			// Pass msg, the requester may have given up already
+			ch := tx.ch
+			if _, ok := msg.(*EventMsg); ok && tx.events != nil {
+				ch = tx.events
+			}
+			select {
+			case ch <- msg:
+			default:
+			}
 		}
 	}
 }
@@ -248,10 +398,18 @@ func (gateway *Gateway) recv() {
 // Info sends an info request to the Gateway.
 // On success, an InfoMsg will be returned and error will be nil.
 func (gateway *Gateway) Info() (*InfoMsg, error) {
+	return gateway.InfoContext(context.Background())
+}
+
+// InfoContext is like Info, but gives up waiting for the response when ctx
+// is done.
+func (gateway *Gateway) InfoContext(ctx context.Context) (*InfoMsg, error) {
 	req, ch := newRequest("info")
-	gateway.send(req, ch)
+	msg, err := gateway.request(ctx, req, ch)
+	if err != nil {
+		return nil, err
+	}
 
-	msg := <-ch
 	switch msg := msg.(type) {
 	case *InfoMsg:
 		return msg, nil
@@ -265,16 +423,26 @@ func (gateway *Gateway) Info() (*InfoMsg, error) {
 // Create sends a create request to the Gateway.
 // On success, a new Session will be returned and error will be nil.
 func (gateway *Gateway) Create() (*Session, error) {
+	return gateway.CreateContext(context.Background())
+}
+
+// CreateContext is like Create, but gives up waiting for the response when
+// ctx is done.
+func (gateway *Gateway) CreateContext(ctx context.Context) (*Session, error) {
 	req, ch := newRequest("create")
-	gateway.send(req, ch)
+	msg, err := gateway.request(ctx, req, ch)
+	if err != nil {
+		return nil, err
+	}
 
-	msg := <-ch
 	var success *SuccessMsg
 	switch msg := msg.(type) {
 	case *SuccessMsg:
 		success = msg
 	case *ErrorMsg:
 		return nil, msg
+	default:
+		return nil, unexpected("create")
 	}
 
 	// Create new session
@@ -282,13 +450,19 @@ func (gateway *Gateway) Create() (*Session, error) {
 	session.gateway = gateway
 	session.ID = success.Data.ID
 	session.Handles = make(map[uint64]*Handle)
-	session.Events = make(chan interface{}, 2)
+	session.events = gateway.newEventQueue()
+	session.Events = session.events.ch
+	session.done = make(chan struct{})
 
 	// Store this session
 	gateway.Lock()
 	gateway.Sessions[session.ID] = session
 	gateway.Unlock()
 
+	if gateway.KeepAliveInterval > 0 {
+		go session.keepAlive()
+	}
+
 	return session, nil
 }
 
@@ -300,41 +474,62 @@ type Session struct {
 	// Handles is a map of plugin handles within this session
 	Handles map[uint64]*Handle
 
+	// Events receives the events of this session. When it is full, events
+	// are dropped or held up according to Gateway.EventOverflow.
 	Events chan interface{}
 
 	// Access to the Handles map should be synchronized with the Session.Lock()
 	// and Session.Unlock() methods provided by the embeded sync.Mutex.
 	sync.Mutex
 
-	gateway *Gateway
+	gateway  *Gateway
+	events   *eventQueue
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func (session *Session) sendContext(ctx context.Context, msg map[string]interface{}, transaction chan interface{}) (*transaction, error) {
+	msg["session_id"] = session.ID
+	return session.gateway.sendContext(ctx, msg, transaction)
 }
 
-func (session *Session) send(msg map[string]interface{}, transaction chan interface{}) {
+func (session *Session) request(ctx context.Context, msg map[string]interface{}, transaction chan interface{}) (interface{}, error) {
 	msg["session_id"] = session.ID
-	session.gateway.send(msg, transaction)
+	return session.gateway.request(ctx, msg, transaction)
 }
 
 // Attach sends an attach request to the Gateway within this session.
 // plugin should be the unique string of the plugin to attach to.
 // On success, a new Handle will be returned and error will be nil.
 func (session *Session) Attach(plugin string) (*Handle, error) {
+	return session.AttachContext(context.Background(), plugin)
+}
+
+// AttachContext is like Attach, but gives up waiting for the response when
+// ctx is done.
+func (session *Session) AttachContext(ctx context.Context, plugin string) (*Handle, error) {
 	req, ch := newRequest("attach")
 	req["plugin"] = plugin
-	session.send(req, ch)
+	msg, err := session.request(ctx, req, ch)
+	if err != nil {
+		return nil, err
+	}
 
 	var success *SuccessMsg
-	msg := <-ch
 	switch msg := msg.(type) {
 	case *SuccessMsg:
 		success = msg
 	case *ErrorMsg:
 		return nil, msg
+	default:
+		return nil, unexpected("attach")
 	}
 
 	handle := new(Handle)
 	handle.session = session
 	handle.ID = success.Data.ID
-	handle.Events = make(chan interface{}, 8)
+	handle.events = session.gateway.newEventQueue()
+	handle.Events = handle.events.ch
 
 	session.Lock()
 	session.Handles[handle.ID] = handle
@@ -346,10 +541,18 @@ func (session *Session) Attach(plugin string) (*Handle, error) {
 // KeepAlive sends a keep-alive request to the Gateway.
 // On success, an AckMsg will be returned and error will be nil.
 func (session *Session) KeepAlive() (*AckMsg, error) {
+	return session.KeepAliveContext(context.Background())
+}
+
+// KeepAliveContext is like KeepAlive, but gives up waiting for the response
+// when ctx is done.
+func (session *Session) KeepAliveContext(ctx context.Context) (*AckMsg, error) {
 	req, ch := newRequest("keepalive")
-	session.send(req, ch)
+	msg, err := session.request(ctx, req, ch)
+	if err != nil {
+		return nil, err
+	}
 
-	msg := <-ch
 	switch msg := msg.(type) {
 	case *AckMsg:
 		return msg, nil
@@ -360,26 +563,66 @@ func (session *Session) KeepAlive() (*AckMsg, error) {
 	return nil, unexpected("keepalive")
 }
 
+// Claim sends a claim request to the Gateway, taking over this session on
+// the current connection, e.g. after reconnecting.
+// On success, a SuccessMsg will be returned and error will be nil.
+func (session *Session) Claim() (*SuccessMsg, error) {
+	return session.ClaimContext(context.Background())
+}
+
+// ClaimContext is like Claim, but gives up waiting for the response when ctx
+// is done.
+func (session *Session) ClaimContext(ctx context.Context) (*SuccessMsg, error) {
+	req, ch := newRequest("claim")
+	msg, err := session.request(ctx, req, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	switch msg := msg.(type) {
+	case *SuccessMsg:
+		return msg, nil
+	case *ErrorMsg:
+		return nil, msg
+	}
+
+	return nil, unexpected("claim")
+}
+
 // Destroy sends a destroy request to the Gateway to tear down this session.
 // On success, the Session will be removed from the Gateway.Sessions map, an
 // AckMsg will be returned and error will be nil.
 func (session *Session) Destroy() (*AckMsg, error) {
+	return session.DestroyContext(context.Background())
+}
+
+// DestroyContext is like Destroy, but gives up waiting for the response when
+// ctx is done.
+func (session *Session) DestroyContext(ctx context.Context) (*AckMsg, error) {
 	req, ch := newRequest("destroy")
-	session.send(req, ch)
+	msg, err := session.request(ctx, req, ch)
+	if err != nil {
+		return nil, err
+	}
 
 	var ack *AckMsg
-	msg := <-ch
 	switch msg := msg.(type) {
 	case *AckMsg:
 		ack = msg
+	case *SuccessMsg:
+		// Janus answers destroy with a success, like an ack without data
+		ack = &AckMsg{}
 	case *ErrorMsg:
 		return nil, msg
+	default:
+		return nil, unexpected("destroy")
 	}
 
 	// Remove this session from the gateway
 	session.gateway.Lock()
 	delete(session.gateway.Sessions, session.ID)
 	session.gateway.Unlock()
+	session.stopKeepAlive()
 
 	return ack, nil
 }
@@ -396,34 +639,54 @@ type Handle struct {
 	User string
 
 	// Events is a receive only channel that can be used to receive events
-	// related to this handle from the gateway.
+	// related to this handle from the gateway. When it is full, events are
+	// dropped or held up according to Gateway.EventOverflow.
 	Events chan interface{}
 
 	session *Session
+	events  *eventQueue
+}
+
+func (handle *Handle) sendContext(ctx context.Context, msg map[string]interface{}, transaction chan interface{}) (*transaction, error) {
+	msg["handle_id"] = handle.ID
+	return handle.session.sendContext(ctx, msg, transaction)
 }
 
-func (handle *Handle) send(msg map[string]interface{}, transaction chan interface{}) {
+func (handle *Handle) request(ctx context.Context, msg map[string]interface{}, transaction chan interface{}) (interface{}, error) {
 	msg["handle_id"] = handle.ID
-	handle.session.send(msg, transaction)
+	return handle.session.request(ctx, msg, transaction)
 }
 
-// Request sends a sync request
+// Request sends a message request to a plugin handle on the Gateway, for
+// plugin requests which are answered synchronously.
+// On success, a SuccessMsg carrying the plugin response will be returned and
+// error will be nil.
 func (handle *Handle) Request(body interface{}) (*SuccessMsg, error) {
-	req, ch := newRequest("message")
-	if body != nil {
-		req["body"] = body
+	return handle.RequestContext(context.Background(), body)
+}
+
+// RequestContext is like Request, but gives up waiting for the response when
+// ctx is done.
+func (handle *Handle) RequestContext(ctx context.Context, body interface{}) (*SuccessMsg, error) {
+	ctx, cancel := handle.session.gateway.withTimeout(ctx)
+	defer cancel()
+
+	pending, err := handle.MessageAsyncContext(ctx, body, nil)
+	if err != nil {
+		return nil, err
 	}
-	handle.send(req, ch)
 
-	response := <-handle.Events //ch
+	reply, err := pending.Reply(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	switch response := response.(type) {
+	switch reply := reply.(type) {
 	case *SuccessMsg:
-		return response, nil
-	case *ErrorMsg:
-		return nil, response
+		return reply, nil
 	}
 
+	pending.Cancel()
 	return nil, unexpected("message")
 }
 
@@ -432,6 +695,46 @@ func (handle *Handle) Request(body interface{}) (*SuccessMsg, error) {
 // contain an optional SDP offer/answer to establish a WebRTC PeerConnection.
 // On success, an EventMsg will be returned and error will be nil.
 func (handle *Handle) Message(body, jsep interface{}) (*EventMsg, error) {
+	return handle.MessageContext(context.Background(), body, jsep)
+}
+
+// MessageContext is like Message, but gives up waiting for the response
+// when ctx is done.
+func (handle *Handle) MessageContext(ctx context.Context, body, jsep interface{}) (*EventMsg, error) {
+	ctx, cancel := handle.session.gateway.withTimeout(ctx)
+	defer cancel()
+
+	pending, err := handle.MessageAsyncContext(ctx, body, jsep)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := pending.Reply(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := reply.(*AckMsg); !ok {
+		pending.Cancel()
+		return nil, unexpected("message")
+	}
+
+	return pending.Event(ctx)
+}
+
+// MessageAsync sends a message request to a plugin handle on the Gateway
+// without waiting for its responses, which can be awaited separately with
+// the returned PendingMessage. This allows several messages to be in flight
+// on the same handle.
+func (handle *Handle) MessageAsync(body, jsep interface{}) (*PendingMessage, error) {
+	return handle.MessageAsyncContext(context.Background(), body, jsep)
+}
+
+// MessageAsyncContext is like MessageAsync, but gives up sending the message
+// when ctx is done.
+func (handle *Handle) MessageAsyncContext(ctx context.Context, body, jsep interface{}) (*PendingMessage, error) {
+	ctx, cancel := handle.session.gateway.withTimeout(ctx)
+	defer cancel()
+
 	req, ch := newRequest("message")
 	if body != nil {
 		req["body"] = body
@@ -439,14 +742,45 @@ func (handle *Handle) Message(body, jsep interface{}) (*EventMsg, error) {
 	if jsep != nil {
 		req["jsep"] = jsep
 	}
-	handle.send(req, ch)
+	tx, err := handle.sendContext(ctx, req, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PendingMessage{
+		Transaction: tx.id.String(),
+		gateway:     handle.session.gateway,
+		tx:          tx,
+	}, nil
+}
+
+// PendingMessage is a message request sent to a plugin handle. Janus answers
+// a message with either an ack, which is followed by an asynchronous plugin
+// event carrying the same transaction, or with a synchronous success reply.
+type PendingMessage struct {
+	// Transaction is the transaction of the message request.
+	Transaction string
+
+	gateway *Gateway
+	tx      *transaction
+}
+
+// Reply waits for the immediate reply to the message.
+// On success, an AckMsg or, for synchronous plugin requests, a SuccessMsg
+// will be returned and error will be nil.
+// If ctx is done first, or after Gateway.RequestTimeout, the message is
+// cancelled.
+func (pending *PendingMessage) Reply(ctx context.Context) (interface{}, error) {
+	ctx, cancel := pending.gateway.withTimeout(ctx)
+	defer cancel()
+
+	msg, err := pending.gateway.wait(ctx, pending.tx)
+	if err != nil {
+		return nil, err
+	}
 
-GetMessage: // No tears..
-	msg := <-ch
 	switch msg := msg.(type) {
-	case *AckMsg:
-		goto GetMessage // ..only dreams.
-	case *EventMsg:
+	case *AckMsg, *SuccessMsg:
 		return msg, nil
 	case *ErrorMsg:
 		return nil, msg
@@ -455,6 +789,43 @@ GetMessage: // No tears..
 	return nil, unexpected("message")
 }
 
+// Event waits for the asynchronous plugin event answering the message.
+// It can be called before or after Reply. There is no event if the reply
+// was an error, which is returned, or a SuccessMsg, in which case
+// ErrNoEvent is returned.
+// On success, an EventMsg will be returned and error will be nil.
+// If ctx is done first, or after Gateway.RequestTimeout, the message is
+// cancelled.
+func (pending *PendingMessage) Event(ctx context.Context) (*EventMsg, error) {
+	gateway := pending.gateway
+	ctx, cancel := gateway.withTimeout(ctx)
+	defer cancel()
+
+	select {
+	case msg := <-pending.tx.events:
+		return msg.(*EventMsg), nil
+	case <-pending.tx.replied:
+		if errMsg, ok := pending.tx.reply.(*ErrorMsg); ok {
+			return nil, errMsg
+		}
+		return nil, ErrNoEvent
+	case <-pending.tx.expired:
+		return nil, pending.tx.failure()
+	case <-ctx.Done():
+		gateway.transactions.remove(pending.tx.id)
+		return nil, ctx.Err()
+	case <-gateway.closed:
+		gateway.transactions.remove(pending.tx.id)
+		return nil, ErrClosed
+	}
+}
+
+// Cancel stops tracking the message, responses received afterwards are
+// delivered to Handle.Events.
+func (pending *PendingMessage) Cancel() {
+	pending.gateway.transactions.remove(pending.tx.id)
+}
+
 // Trickle sends a trickle request to the Gateway as part of establishing
 // a new PeerConnection with a plugin.
 // candidate should be a single ICE candidate, or a completed object to
@@ -464,11 +835,19 @@ GetMessage: // No tears..
 //		}
 // On success, an AckMsg will be returned and error will be nil.
 func (handle *Handle) Trickle(candidate interface{}) (*AckMsg, error) {
+	return handle.TrickleContext(context.Background(), candidate)
+}
+
+// TrickleContext is like Trickle, but gives up waiting for the response
+// when ctx is done.
+func (handle *Handle) TrickleContext(ctx context.Context, candidate interface{}) (*AckMsg, error) {
 	req, ch := newRequest("trickle")
 	req["candidate"] = candidate
-	handle.send(req, ch)
+	msg, err := handle.request(ctx, req, ch)
+	if err != nil {
+		return nil, err
+	}
 
-	msg := <-ch
 	switch msg := msg.(type) {
 	case *AckMsg:
 		return msg, nil
@@ -484,11 +863,19 @@ func (handle *Handle) Trickle(candidate interface{}) (*AckMsg, error) {
 // candidates should be an array of ICE candidates.
 // On success, an AckMsg will be returned and error will be nil.
 func (handle *Handle) TrickleMany(candidates interface{}) (*AckMsg, error) {
+	return handle.TrickleManyContext(context.Background(), candidates)
+}
+
+// TrickleManyContext is like TrickleMany, but gives up waiting for the
+// response when ctx is done.
+func (handle *Handle) TrickleManyContext(ctx context.Context, candidates interface{}) (*AckMsg, error) {
 	req, ch := newRequest("trickle")
 	req["candidates"] = candidates
-	handle.send(req, ch)
+	msg, err := handle.request(ctx, req, ch)
+	if err != nil {
+		return nil, err
+	}
 
-	msg := <-ch
 	switch msg := msg.(type) {
 	case *AckMsg:
 		return msg, nil
@@ -502,16 +889,29 @@ func (handle *Handle) TrickleMany(candidates interface{}) (*AckMsg, error) {
 // Detach sends a detach request to the Gateway to remove this handle.
 // On success, an AckMsg will be returned and error will be nil.
 func (handle *Handle) Detach() (*AckMsg, error) {
+	return handle.DetachContext(context.Background())
+}
+
+// DetachContext is like Detach, but gives up waiting for the response when
+// ctx is done.
+func (handle *Handle) DetachContext(ctx context.Context) (*AckMsg, error) {
 	req, ch := newRequest("detach")
-	handle.send(req, ch)
+	msg, err := handle.request(ctx, req, ch)
+	if err != nil {
+		return nil, err
+	}
 
 	var ack *AckMsg
-	msg := <-ch
 	switch msg := msg.(type) {
 	case *AckMsg:
 		ack = msg
+	case *SuccessMsg:
+		// Janus answers detach with a success, like an ack without data
+		ack = &AckMsg{}
 	case *ErrorMsg:
 		return nil, msg
+	default:
+		return nil, unexpected("detach")
 	}
 
 	// Remove this handle from the session
@@ -1,11 +1,17 @@
 package janus
 
 import (
+	"fmt"
 	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
 )
 
 func Test_Connect(t *testing.T) {
-	client, err := Connect("ws://localhost:8188/")
+	server := janustest.NewServer()
+	defer server.Close()
+
+	client, err := Connect(server.URL)
 	if err != nil {
 		t.Fail()
 		return
@@ -25,3 +31,42 @@ func Test_Connect(t *testing.T) {
 	//t.Log(sess)
 	//t.Log("connect")
 }
+
+func TestGateway_DestroyDetachSuccess(t *testing.T) {
+	// Janus answers destroy and detach with a success rather than an ack
+	gateway := NewGateway(newMemTransport(func(req map[string]interface{}) []string {
+		tx := req["transaction"].(string)
+		switch req["janus"] {
+		case "create":
+			return []string{fmt.Sprintf(`{"janus":"success","transaction":"%s","data":{"id":1}}`, tx)}
+		case "attach":
+			return []string{fmt.Sprintf(`{"janus":"success","session_id":1,"transaction":"%s","data":{"id":2}}`, tx)}
+		case "detach", "destroy":
+			return []string{fmt.Sprintf(`{"janus":"success","session_id":1,"transaction":"%s"}`, tx)}
+		}
+		return nil
+	}))
+	defer gateway.Close()
+
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle, err := session.Attach("janus.plugin.echotest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := handle.Detach(); err != nil {
+		t.Errorf("detach: %v", err)
+	}
+	if _, err := session.Destroy(); err != nil {
+		t.Errorf("destroy: %v", err)
+	}
+	gateway.Lock()
+	_, ok := gateway.Sessions[session.ID]
+	gateway.Unlock()
+	if ok {
+		t.Error("destroyed session still tracked by the gateway")
+	}
+}
@@ -0,0 +1,390 @@
+package janustest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (s *Server) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Like Janus, take the session and handle from the path, e.g.
+	// /admin/<session>/<handle>.
+	path := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin"), "/"), "/")
+	if len(path) > 0 && path[0] != "" {
+		req["session_id"], _ = strconv.ParseFloat(path[0], 64)
+	}
+	if len(path) > 1 {
+		req["handle_id"], _ = strconv.ParseFloat(path[1], 64)
+	}
+
+	// A scripted failure answers the request without acting on it, so the
+	// state of the fake agrees with the reply.
+	request, _ := req["janus"].(string)
+	var resp map[string]interface{}
+	if err, delay := s.scripted(request); err != nil {
+		resp = errorMsg(req["transaction"], err)
+	} else {
+		resp = s.adminRequest(request, req)
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) adminRequest(request string, req map[string]interface{}) map[string]interface{} {
+	tx := req["transaction"]
+	txID, _ := tx.(string)
+	sessionID := toUint64(req["session_id"])
+	handleID := toUint64(req["handle_id"])
+
+	reply := func(fields map[string]interface{}) map[string]interface{} {
+		msg := map[string]interface{}{
+			"janus":       "success",
+			"transaction": tx,
+		}
+		for k, v := range fields {
+			msg[k] = v
+		}
+		return msg
+	}
+	fail := func(code int, reason string) map[string]interface{} {
+		return errorMsg(tx, &Error{Code: code, Reason: reason})
+	}
+
+	switch request {
+	case "info":
+		info := s.info()
+		info["janus"] = "server_info"
+		info["transaction"] = tx
+		return info
+	case "ping":
+		return map[string]interface{}{"janus": "pong", "transaction": tx}
+	case "":
+		return fail(ErrorMissingRequest, "Missing mandatory element (janus)")
+	}
+
+	if secret, _ := req["admin_secret"].(string); s.AdminSecret != "" && secret != s.AdminSecret {
+		return fail(ErrorUnauthorized, "Unauthorized request (wrong or missing secret/token)")
+	}
+
+	if request == "message_plugin" {
+		plugin, _ := req["plugin"].(string)
+		s.mu.Lock()
+		handler := s.plugins[plugin]
+		s.mu.Unlock()
+		if handler == nil {
+			return fail(ErrorPluginNotFound, "No such plugin")
+		}
+
+		resp := handler(&PluginMessage{
+			Plugin:      plugin,
+			Transaction: txID,
+			Body:        toMap(req["request"]),
+		})
+		var data map[string]interface{}
+		if resp != nil {
+			data = resp.Data
+		}
+		return reply(map[string]interface{}{"response": data})
+	}
+
+	if request == "message_handle" {
+		s.mu.Lock()
+		var plugin string
+		var handleOK bool
+		if sess := s.sessions[sessionID]; sess != nil {
+			plugin, handleOK = sess.handles[handleID]
+		}
+		handler := s.plugins[plugin]
+		s.mu.Unlock()
+		if !handleOK {
+			return fail(ErrorHandleNotFound, "No such handle")
+		}
+		if handler == nil {
+			return fail(ErrorPluginMessage, "No handler for plugin "+plugin)
+		}
+
+		resp := handler(&PluginMessage{
+			Plugin:      plugin,
+			Session:     sessionID,
+			Handle:      handleID,
+			Transaction: txID,
+			Body:        toMap(req["request"]),
+		})
+		var data map[string]interface{}
+		if resp != nil {
+			data = resp.Data
+		}
+		return reply(map[string]interface{}{"response": data})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, _ := req["token"].(string)
+	plugins := toStrings(req["plugins"])
+
+	switch request {
+	case "list_sessions":
+		return reply(map[string]interface{}{"sessions": s.sessionIDs()})
+	case "list_tokens":
+		tokens := make([]map[string]interface{}, 0, len(s.tokens))
+		for t, allowed := range s.tokens {
+			tokens = append(tokens, map[string]interface{}{"token": t, "allowed_plugins": allowed})
+		}
+		return reply(map[string]interface{}{"data": map[string]interface{}{"tokens": tokens}})
+	case "add_token":
+		if _, ok := s.tokens[token]; ok {
+			return fail(ErrorUnknown, "Token already exists")
+		}
+		s.tokens[token] = plugins
+		return reply(map[string]interface{}{"data": map[string]interface{}{"plugins": plugins}})
+	case "allow_token", "disallow_token":
+		allowed, ok := s.tokens[token]
+		if !ok {
+			return fail(ErrorTokenNotFound, "Token not found")
+		}
+		for _, plugin := range plugins {
+			allowed = removeString(allowed, plugin)
+			if request == "allow_token" {
+				allowed = append(allowed, plugin)
+			}
+		}
+		s.tokens[token] = allowed
+		return reply(map[string]interface{}{"data": map[string]interface{}{"plugins": allowed}})
+	case "remove_token":
+		if _, ok := s.tokens[token]; !ok {
+			return fail(ErrorTokenNotFound, "Token not found")
+		}
+		delete(s.tokens, token)
+		return reply(nil)
+	case "get_status":
+		status := make(map[string]interface{}, len(s.status)+1)
+		for k, v := range s.status {
+			status[k] = v
+		}
+		status["token_auth"] = s.TokenAuth
+		return reply(map[string]interface{}{"status": status})
+	case "accept_new_sessions":
+		accept, ok := req["accept"].(bool)
+		if !ok {
+			return fail(ErrorInvalidElement, "Invalid element type (accept should be a boolean)")
+		}
+		s.refusing = !accept
+		return reply(map[string]interface{}{"accept": accept})
+	}
+
+	if setting, ok := settings[request]; ok {
+		value, ok := req[setting.param]
+		if !ok {
+			return fail(ErrorMissingElement, "Missing mandatory element ("+setting.param+")")
+		}
+		s.status[setting.status] = value
+		return reply(map[string]interface{}{setting.response: value})
+	}
+
+	sess := s.sessions[sessionID]
+	if sess == nil {
+		return fail(ErrorSessionNotFound, "No such session")
+	}
+
+	switch request {
+	case "list_handles":
+		return reply(map[string]interface{}{
+			"session_id": sessionID,
+			"handles":    s.handleIDs(sessionID),
+		})
+	case "destroy_session":
+		delete(s.sessions, sessionID)
+		return reply(nil)
+	}
+
+	plugin, ok := sess.handles[handleID]
+	if !ok {
+		return fail(ErrorHandleNotFound, "No such handle")
+	}
+
+	// notify sends an event about the handle to the connection owning the
+	// session, as Janus does when the Admin API acts on the handle.
+	notify := func(event string, fields map[string]interface{}) {
+		if sess.conn == nil {
+			return
+		}
+		msg := map[string]interface{}{
+			"janus":      event,
+			"session_id": sessionID,
+			"sender":     handleID,
+		}
+		for k, v := range fields {
+			msg[k] = v
+		}
+		sess.conn.write(msg)
+	}
+
+	switch request {
+	case "detach_handle":
+		delete(sess.handles, handleID)
+		notify("detached", nil)
+		return reply(nil)
+	case "hangup_webrtc":
+		notify("hangup", map[string]interface{}{"reason": "Admin API"})
+		return reply(nil)
+	case "start_pcap", "stop_pcap", "start_text2pcap", "stop_text2pcap":
+		return reply(nil)
+	case "handle_info":
+		return reply(map[string]interface{}{
+			"session_id": sessionID,
+			"handle_id":  handleID,
+			"info":       handleInfo(sessionID, handleID, plugin),
+		})
+	}
+
+	return fail(ErrorUnknownRequest, "Unknown request '"+request+"'")
+}
+
+// handleInfo returns the info of a handle whose PeerConnection is up, with
+// one bundled stream carrying audio and video.
+func handleInfo(sessionID, handleID uint64, plugin string) map[string]interface{} {
+	stats := func(packets int) map[string]interface{} {
+		return map[string]interface{}{
+			"audio_packets":       packets,
+			"audio_bytes":         packets * 100,
+			"audio_bytes_lastsec": 4000,
+			"video_packets":       packets * 2,
+			"video_bytes":         packets * 1000,
+			"video_bytes_lastsec": 60000,
+			"video_nacks":         3,
+		}
+	}
+	return map[string]interface{}{
+		"session_id":        sessionID,
+		"session_transport": "janus.transport.websockets",
+		"handle_id":         handleID,
+		"plugin":            plugin,
+		"plugin_specific":   map[string]interface{}{"type": "publisher", "room": 1234},
+		"flags": map[string]interface{}{
+			"got-offer":  true,
+			"got-answer": true,
+			"negotiated": true,
+			"ready":      true,
+			"trickle":    true,
+			"has-audio":  true,
+			"has-video":  true,
+		},
+		"ice-mode": "full",
+		"ice-role": "controlled",
+		"sdps": map[string]interface{}{
+			"profile": "UDP/TLS/RTP/SAVPF",
+			"local":   "v=0\r\n",
+			"remote":  "v=0\r\n",
+		},
+		"streams": []interface{}{
+			map[string]interface{}{
+				"id":    1,
+				"ready": -1,
+				"ssrc":  map[string]interface{}{"audio": 1111, "video": 2222, "audio-peer": 3333, "video-peer": 4444},
+				"codecs": map[string]interface{}{
+					"audio-pt":    111,
+					"audio-codec": "opus",
+					"video-pt":    96,
+					"video-codec": "vp8",
+				},
+				"rtcp_stats": map[string]interface{}{
+					"audio": map[string]interface{}{"base": 48000, "rtt": 20, "lost": 1, "in-link-quality": 100, "out-link-quality": 99},
+				},
+				"components": []interface{}{
+					map[string]interface{}{
+						"id":                1,
+						"state":             "connected",
+						"local-candidates":  []interface{}{"1 1 udp 2015363327 192.0.2.1 40000 typ host"},
+						"remote-candidates": []interface{}{"1 1 udp 2015363327 198.51.100.1 50000 typ host"},
+						"selected-pair":     "192.0.2.1:40000 [host,udp] <-> 198.51.100.1:50000 [host,udp]",
+						"dtls": map[string]interface{}{
+							"fingerprint":             "D2:B9:31:8F:DF:24:D8:0E:ED:D2:EF:25:9E:AF:6F:B8:34:AE:53:9C:E6:F3:8F:F2:64:15:FA:E8:7F:53:2D:38",
+							"remote-fingerprint":      "6E:0E:8F:1B:75:2C:0F:53:B4:4D:3F:5A:4F:42:9E:C9:1E:8A:5C:F6:52:74:8C:63:6E:8B:0B:D4:C2:1D:2E:9A",
+							"remote-fingerprint-hash": "sha-256",
+							"dtls-role":               "active",
+							"dtls-state":              "connected",
+							"valid":                   true,
+							"srtp-profile":            "SRTP_AES128_CM_SHA1_80",
+							"ready":                   true,
+						},
+						"in_stats":  stats(500),
+						"out_stats": stats(400),
+					},
+				},
+			},
+		},
+	}
+}
+
+// settings maps the set_* requests to the parameter carrying the new value,
+// the key of the setting in the get_status response, and the key of the
+// value in the response to the request.
+var settings = map[string]struct{ param, status, response string }{
+	"set_session_timeout":    {"timeout", "session_timeout", "timeout"},
+	"set_log_level":          {"level", "log_level", "level"},
+	"set_log_timestamps":     {"timestamps", "log_timestamps", "log_timestamps"},
+	"set_log_colors":         {"colors", "log_colors", "log_colors"},
+	"set_locking_debug":      {"debug", "locking_debug", "locking_debug"},
+	"set_refcount_debug":     {"debug", "refcount_debug", "refcount_debug"},
+	"set_libnice_debug":      {"debug", "libnice_debug", "libnice_debug"},
+	"set_min_nack_queue":     {"min_nack_queue", "min_nack_queue", "min_nack_queue"},
+	"set_no_media_timer":     {"no_media_timer", "no_media_timer", "no_media_timer"},
+	"set_slowlink_threshold": {"slowlink_threshold", "slowlink_threshold", "slowlink_threshold"},
+}
+
+// defaultStatus returns the get_status settings of a freshly started Janus.
+func defaultStatus() map[string]interface{} {
+	return map[string]interface{}{
+		"session_timeout":         60,
+		"reclaim_session_timeout": 0,
+		"candidates_timeout":      45,
+		"log_level":               4,
+		"log_timestamps":          false,
+		"log_colors":              true,
+		"locking_debug":           false,
+		"refcount_debug":          false,
+		"libnice_debug":           false,
+		"min_nack_queue":          200,
+		"nack-optimizations":      false,
+		"no_media_timer":          1,
+		"slowlink_threshold":      0,
+	}
+}
+
+func toStrings(v interface{}) []string {
+	list, _ := v.([]interface{})
+	strs := make([]string, 0, len(list))
+	for _, x := range list {
+		if str, ok := x.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
+func removeString(list []string, str string) []string {
+	for i, x := range list {
+		if x == str {
+			return append(list[:i:i], list[i+1:]...)
+		}
+	}
+	return list
+}
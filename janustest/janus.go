@@ -0,0 +1,212 @@
+package janustest
+
+import (
+	"net/http"
+	"time"
+)
+
+func (s *Server) serveJanus(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &conn{ws: ws}
+	s.mu.Lock()
+	s.conns[c] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		for _, sess := range s.sessions {
+			if sess.conn == c {
+				sess.conn = nil
+			}
+		}
+		s.mu.Unlock()
+		ws.Close()
+	}()
+
+	for {
+		var req map[string]interface{}
+		if err := ws.ReadJSON(&req); err != nil {
+			return
+		}
+
+		// A scripted failure answers the request without acting on it, so
+		// the state of the fake agrees with the reply.
+		request, _ := req["janus"].(string)
+		err, delay := s.scripted(request)
+		if err != nil {
+			c.write(errorMsg(req["transaction"], err))
+			continue
+		}
+
+		responses := s.janusRequest(c, request, req)
+		if delay > 0 {
+			go func() {
+				time.Sleep(delay)
+				for _, resp := range responses {
+					c.write(resp)
+				}
+			}()
+			continue
+		}
+
+		for _, resp := range responses {
+			c.write(resp)
+		}
+	}
+}
+
+func (s *Server) janusRequest(c *conn, request string, req map[string]interface{}) []map[string]interface{} {
+	tx := req["transaction"]
+	txID, _ := tx.(string)
+	sessionID := toUint64(req["session_id"])
+	handleID := toUint64(req["handle_id"])
+
+	reply := func(typ string, fields map[string]interface{}) []map[string]interface{} {
+		msg := map[string]interface{}{
+			"janus":       typ,
+			"transaction": tx,
+		}
+		if sessionID != 0 {
+			msg["session_id"] = sessionID
+		}
+		if handleID != 0 {
+			msg["sender"] = handleID
+		}
+		for k, v := range fields {
+			msg[k] = v
+		}
+		return []map[string]interface{}{msg}
+	}
+	fail := func(code int, reason string) []map[string]interface{} {
+		return []map[string]interface{}{errorMsg(tx, &Error{Code: code, Reason: reason})}
+	}
+
+	switch request {
+	case "info":
+		return reply("server_info", s.info())
+	case "ping":
+		return reply("pong", nil)
+	case "":
+		return fail(ErrorMissingRequest, "Missing mandatory element (janus)")
+	}
+
+	s.mu.Lock()
+	if s.TokenAuth {
+		token, _ := req["token"].(string)
+		if _, ok := s.tokens[token]; !ok {
+			s.mu.Unlock()
+			return fail(ErrorUnauthorized, "Unauthorized request (wrong or missing secret/token)")
+		}
+	}
+
+	if request == "create" {
+		if s.refusing {
+			s.mu.Unlock()
+			return fail(ErrorNotAccepting, "Not accepting new sessions")
+		}
+		id := s.newID()
+		s.sessions[id] = &session{id: id, conn: c, handles: make(map[uint64]string)}
+		s.mu.Unlock()
+		return reply("success", map[string]interface{}{"data": map[string]interface{}{"id": id}})
+	}
+
+	sess := s.sessions[sessionID]
+	if sess == nil {
+		s.mu.Unlock()
+		return fail(ErrorSessionNotFound, "No such session")
+	}
+
+	switch request {
+	case "keepalive":
+		s.mu.Unlock()
+		return reply("ack", nil)
+	case "claim":
+		sess.conn = c
+		s.mu.Unlock()
+		return reply("success", nil)
+	case "destroy":
+		delete(s.sessions, sessionID)
+		s.mu.Unlock()
+		return reply("success", nil)
+	case "attach":
+		plugin, _ := req["plugin"].(string)
+		id := s.newID()
+		sess.handles[id] = plugin
+		s.mu.Unlock()
+		return reply("success", map[string]interface{}{"data": map[string]interface{}{"id": id}})
+	}
+
+	plugin, ok := sess.handles[handleID]
+	if !ok {
+		s.mu.Unlock()
+		return fail(ErrorHandleNotFound, "No such handle")
+	}
+
+	switch request {
+	case "detach":
+		delete(sess.handles, handleID)
+		s.mu.Unlock()
+		return reply("success", nil)
+	case "trickle":
+		s.mu.Unlock()
+		return reply("ack", nil)
+	case "hangup":
+		s.mu.Unlock()
+		return reply("success", nil)
+	case "message":
+		handler := s.plugins[plugin]
+		s.mu.Unlock()
+		if handler == nil {
+			return fail(ErrorPluginMessage, "No handler for plugin "+plugin)
+		}
+
+		resp := handler(&PluginMessage{
+			Plugin:      plugin,
+			Session:     sessionID,
+			Handle:      handleID,
+			Transaction: txID,
+			Body:        toMap(req["body"]),
+			Jsep:        toMap(req["jsep"]),
+		})
+		if resp == nil {
+			return reply("ack", nil)
+		}
+
+		fields := map[string]interface{}{
+			"plugindata": map[string]interface{}{"plugin": plugin, "data": resp.Data},
+		}
+		if resp.Jsep != nil {
+			fields["jsep"] = resp.Jsep
+		}
+		if !resp.Async {
+			return reply("success", fields)
+		}
+		return append(reply("ack", nil), reply("event", fields)...)
+	}
+
+	s.mu.Unlock()
+	return fail(ErrorUnknownRequest, "Unknown request '"+request+"'")
+}
+
+func (s *Server) info() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plugins := make(map[string]interface{}, len(s.plugins))
+	for name := range s.plugins {
+		plugins[name] = map[string]interface{}{"name": name}
+	}
+	return map[string]interface{}{
+		"name":           "Janus WebRTC Server",
+		"version":        1000,
+		"version_string": "1.0.0",
+		"author":         "janustest",
+		"server-name":    "janustest",
+		"plugins":        plugins,
+	}
+}
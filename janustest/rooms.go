@@ -0,0 +1,110 @@
+package janustest
+
+import (
+	"strings"
+	"sync"
+)
+
+// roomErrors are the error codes of a room based plugin.
+type roomErrors struct {
+	invalidRequest int
+	noSuchRoom     int
+	roomExists     int
+	unauthorized   int
+}
+
+var pluginRoomErrors = map[string]roomErrors{
+	"audiobridge": {483, 485, 486, 487},
+	"textroom":    {415, 417, 418, 419},
+	"videoroom":   {423, 426, 427, 433},
+}
+
+// Rooms returns a PluginHandler keeping the rooms of a room based plugin
+// in memory, answering its create, list, exists, edit and destroy requests.
+// name is the short name of the plugin (e.g. "videoroom"), used as key of
+// the responses. Other requests are answered with an error, so tests can
+// wrap the handler to implement them.
+func Rooms(name string) PluginHandler {
+	codes := pluginRoomErrors[name]
+	var mu sync.Mutex
+	rooms := make(map[float64]map[string]interface{})
+
+	reply := func(result string, fields map[string]interface{}) *PluginResponse {
+		data := map[string]interface{}{name: result}
+		for k, v := range fields {
+			data[k] = v
+		}
+		return &PluginResponse{Data: data}
+	}
+	fail := func(code int, reason string) *PluginResponse {
+		return reply("event", map[string]interface{}{"error_code": code, "error": reason})
+	}
+
+	return func(msg *PluginMessage) *PluginResponse {
+		mu.Lock()
+		defer mu.Unlock()
+
+		request, _ := msg.Body["request"].(string)
+		id, _ := msg.Body["room"].(float64)
+
+		if request == "list" {
+			list := make([]map[string]interface{}, 0, len(rooms))
+			for _, room := range rooms {
+				listed := make(map[string]interface{}, len(room)+2)
+				for k, v := range room {
+					listed[k] = v
+				}
+				delete(listed, "secret")
+				delete(listed, "pin")
+				listed["pin_required"] = room["pin"] != nil
+				listed["max_publishers"] = room["publishers"]
+				list = append(list, listed)
+			}
+			return reply("success", map[string]interface{}{"list": list})
+		}
+
+		if request == "exists" {
+			_, ok := rooms[id]
+			return reply("success", map[string]interface{}{"room": id, "exists": ok})
+		}
+
+		if request == "create" {
+			if _, ok := rooms[id]; ok {
+				return fail(codes.roomExists, "Room already exists")
+			}
+			room := make(map[string]interface{})
+			for k, v := range msg.Body {
+				switch k {
+				case "request", "permanent", "admin_key", "allowed":
+				default:
+					room[k] = v
+				}
+			}
+			rooms[id] = room
+			return reply("created", map[string]interface{}{"room": id, "permanent": msg.Body["permanent"]})
+		}
+
+		room, ok := rooms[id]
+		if !ok {
+			return fail(codes.noSuchRoom, "No such room")
+		}
+		if secret, ok := room["secret"]; ok && msg.Body["secret"] != secret {
+			return fail(codes.unauthorized, "Unauthorized (wrong secret)")
+		}
+
+		switch request {
+		case "edit":
+			for k, v := range msg.Body {
+				if strings.HasPrefix(k, "new_") {
+					room[strings.TrimPrefix(k, "new_")] = v
+				}
+			}
+			return reply("edited", map[string]interface{}{"room": id})
+		case "destroy":
+			delete(rooms, id)
+			return reply("destroyed", map[string]interface{}{"room": id})
+		}
+
+		return fail(codes.invalidRequest, "Unknown request '"+request+"'")
+	}
+}
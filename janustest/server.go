@@ -0,0 +1,324 @@
+// Package janustest provides an in-process fake of the Janus Gateway, to
+// test code built on the janus and admin packages without a running
+// instance of Janus.
+//
+// The fake serves the Janus API over a websocket and the Admin API over
+// HTTP. It implements the core requests of both, while plugin messages are
+// answered by handlers registered by the test. Tests can push events,
+// make requests fail, delay responses and drop connections.
+package janustest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Janus error codes used by the fake.
+const (
+	ErrorUnauthorized    = 403
+	ErrorMissingRequest  = 452
+	ErrorUnknownRequest  = 453
+	ErrorPluginMessage   = 454
+	ErrorMissingElement  = 456
+	ErrorInvalidElement  = 457
+	ErrorSessionNotFound = 458
+	ErrorHandleNotFound  = 459
+	ErrorPluginNotFound  = 460
+	ErrorTokenNotFound   = 470
+	ErrorNotAccepting    = 472
+	ErrorUnknown         = 490
+)
+
+// PluginMessage is a message sent to a plugin, either by a handle through
+// the Janus API, or through message_plugin of the Admin API, in which case
+// Session and Handle are 0.
+type PluginMessage struct {
+	Plugin      string
+	Session     uint64
+	Handle      uint64
+	Transaction string
+	Body        map[string]interface{}
+	Jsep        map[string]interface{}
+}
+
+// PluginResponse is the answer of a plugin to a PluginMessage.
+type PluginResponse struct {
+	// Data is the plugin data of the response.
+	Data map[string]interface{}
+
+	// Jsep is an optional SDP offer/answer sent along Data.
+	Jsep map[string]interface{}
+
+	// Async answers the message with an ack, followed by an event carrying
+	// Data, instead of a synchronous success. It is ignored for Admin API
+	// requests.
+	Async bool
+}
+
+// PluginHandler answers the messages sent to a plugin. A nil response
+// answers a handle message with a bare ack, leaving it to the test to Push
+// the event later.
+type PluginHandler func(msg *PluginMessage) *PluginResponse
+
+// Server is a fake Janus Gateway.
+type Server struct {
+	// URL is the websocket URL of the Janus API.
+	URL string
+
+	// AdminURL is the HTTP URL of the Admin API.
+	AdminURL string
+
+	// AdminSecret, if set, is required from Admin API requests.
+	AdminSecret string
+
+	// TokenAuth requires Janus API requests to carry a token added through
+	// the Admin API.
+	TokenAuth bool
+
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu       sync.Mutex
+	nextID   uint64
+	sessions map[uint64]*session
+	conns    map[*conn]bool
+	tokens   map[string][]string
+	status   map[string]interface{}
+	refusing bool
+	plugins  map[string]PluginHandler
+	failures map[string][]*Error
+	delays   map[string]time.Duration
+}
+
+type session struct {
+	id      uint64
+	conn    *conn
+	handles map[uint64]string
+}
+
+type conn struct {
+	ws *websocket.Conn
+	mu sync.Mutex
+}
+
+func (c *conn) write(msg map[string]interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ws.WriteJSON(msg)
+}
+
+// Error is a Janus API or Admin API error.
+type Error struct {
+	Code   int    `json:"code"`
+	Reason string `json:"reason"`
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("[%d] %s", err.Code, err.Reason)
+}
+
+// NewServer starts a fake Janus Gateway. It should be closed with Close.
+func NewServer() *Server {
+	s := &Server{
+		nextID:   1000,
+		sessions: make(map[uint64]*session),
+		conns:    make(map[*conn]bool),
+		tokens:   make(map[string][]string),
+		status:   defaultStatus(),
+		plugins:  make(map[string]PluginHandler),
+		failures: make(map[string][]*Error),
+		delays:   make(map[string]time.Duration),
+	}
+	s.upgrader.Subprotocols = []string{"janus-protocol"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serveJanus)
+	mux.HandleFunc("/admin", s.serveAdmin)
+	mux.HandleFunc("/admin/", s.serveAdmin)
+	s.server = httptest.NewServer(mux)
+
+	addr := strings.TrimPrefix(s.server.URL, "http://")
+	s.URL = "ws://" + addr + "/"
+	s.AdminURL = "http://" + addr + "/admin"
+	return s
+}
+
+// Close drops all connections and shuts the server down.
+func (s *Server) Close() {
+	s.Disconnect()
+	s.server.Close()
+}
+
+// HandlePlugin registers the handler answering messages to plugin.
+func (s *Server) HandlePlugin(plugin string, handler PluginHandler) {
+	s.mu.Lock()
+	s.plugins[plugin] = handler
+	s.mu.Unlock()
+}
+
+// FailNext makes the next request of the given type (e.g. "create",
+// "attach" or "list_sessions") fail with the given error.
+func (s *Server) FailNext(request string, code int, reason string) {
+	s.mu.Lock()
+	s.failures[request] = append(s.failures[request], &Error{Code: code, Reason: reason})
+	s.mu.Unlock()
+}
+
+// Delay holds back the responses to requests of the given type.
+// A zero delay removes it.
+func (s *Server) Delay(request string, delay time.Duration) {
+	s.mu.Lock()
+	if delay > 0 {
+		s.delays[request] = delay
+	} else {
+		delete(s.delays, request)
+	}
+	s.mu.Unlock()
+}
+
+// Disconnect drops all websocket connections. Sessions are kept, and can be
+// claimed by a new connection.
+func (s *Server) Disconnect() {
+	s.mu.Lock()
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		c.ws.Close()
+	}
+}
+
+// Sessions returns the IDs of the active sessions.
+func (s *Server) Sessions() []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessionIDs()
+}
+
+// Handles returns the IDs of the handles of a session.
+func (s *Server) Handles(sessionID uint64) []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handleIDs(sessionID)
+}
+
+// PushEvent sends a plugin event to the connection owning the session.
+func (s *Server) PushEvent(sessionID, handleID uint64, data, jsep map[string]interface{}) error {
+	s.mu.Lock()
+	plugin := ""
+	if sess := s.sessions[sessionID]; sess != nil {
+		plugin = sess.handles[handleID]
+	}
+	s.mu.Unlock()
+
+	msg := map[string]interface{}{
+		"janus":      "event",
+		"session_id": sessionID,
+		"sender":     handleID,
+		"plugindata": map[string]interface{}{"plugin": plugin, "data": data},
+	}
+	if jsep != nil {
+		msg["jsep"] = jsep
+	}
+	return s.Push(sessionID, msg)
+}
+
+// Push sends a raw message to the connection owning the session.
+func (s *Server) Push(sessionID uint64, msg map[string]interface{}) error {
+	s.mu.Lock()
+	sess := s.sessions[sessionID]
+	s.mu.Unlock()
+
+	if sess == nil || sess.conn == nil {
+		return &Error{Code: ErrorSessionNotFound, Reason: "No such session"}
+	}
+	return sess.conn.write(msg)
+}
+
+// ExpireSession times a session out, notifying its connection.
+func (s *Server) ExpireSession(sessionID uint64) error {
+	err := s.Push(sessionID, map[string]interface{}{
+		"janus":      "timeout",
+		"session_id": sessionID,
+	})
+
+	s.mu.Lock()
+	delete(s.sessions, sessionID)
+	s.mu.Unlock()
+	return err
+}
+
+func (s *Server) newID() uint64 {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Server) sessionIDs() []uint64 {
+	ids := make([]uint64, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (s *Server) handleIDs(sessionID uint64) []uint64 {
+	sess := s.sessions[sessionID]
+	if sess == nil {
+		return nil
+	}
+	ids := make([]uint64, 0, len(sess.handles))
+	for id := range sess.handles {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// scripted returns the error and delay scripted for a request type.
+func (s *Server) scripted(request string) (*Error, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err *Error
+	if failures := s.failures[request]; len(failures) > 0 {
+		err = failures[0]
+		s.failures[request] = failures[1:]
+	}
+	return err, s.delays[request]
+}
+
+func errorMsg(transaction interface{}, err *Error) map[string]interface{} {
+	return map[string]interface{}{
+		"janus":       "error",
+		"transaction": transaction,
+		"error":       err,
+	}
+}
+
+func toUint64(v interface{}) uint64 {
+	switch v := v.(type) {
+	case float64:
+		return uint64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return uint64(n)
+	}
+	return 0
+}
+
+func toMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
@@ -0,0 +1,268 @@
+package janustest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tatsujin1/janus-go"
+	"github.com/tatsujin1/janus-go/admin"
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func connect(t *testing.T, server *janustest.Server) (*janus.Gateway, *janus.Session, *janus.Handle) {
+	gateway, err := janus.Connect(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle, err := session.Attach("janus.plugin.echotest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gateway, session, handle
+}
+
+func nextEvent(t *testing.T, ch chan interface{}) interface{} {
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+func TestServer_Lifecycle(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	gateway, session, handle := connect(t, server)
+	defer gateway.Close()
+
+	if ids := server.Sessions(); len(ids) != 1 || ids[0] != session.ID {
+		t.Errorf("expected session %d, got %v", session.ID, ids)
+	}
+	if ids := server.Handles(session.ID); len(ids) != 1 || ids[0] != handle.ID {
+		t.Errorf("expected handle %d, got %v", handle.ID, ids)
+	}
+
+	if _, err := session.KeepAlive(); err != nil {
+		t.Error(err)
+	}
+	if _, err := handle.Trickle(map[string]interface{}{"completed": true}); err != nil {
+		t.Error(err)
+	}
+	if _, err := handle.Detach(); err != nil {
+		t.Error(err)
+	}
+	if ids := server.Handles(session.ID); len(ids) != 0 {
+		t.Errorf("expected no handles after detach, got %v", ids)
+	}
+	if _, err := session.Destroy(); err != nil {
+		t.Error(err)
+	}
+	if ids := server.Sessions(); len(ids) != 0 {
+		t.Errorf("expected no sessions after destroy, got %v", ids)
+	}
+}
+
+func TestServer_PluginMessages(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	server.HandlePlugin("janus.plugin.echotest", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		if _, ok := msg.Body["async"]; ok {
+			return &janustest.PluginResponse{
+				Data:  map[string]interface{}{"echotest": "event", "result": "ok"},
+				Jsep:  map[string]interface{}{"type": "answer", "sdp": "v=0"},
+				Async: true,
+			}
+		}
+		return &janustest.PluginResponse{Data: map[string]interface{}{"echotest": "success"}}
+	})
+
+	gateway, _, handle := connect(t, server)
+	defer gateway.Close()
+
+	success, err := handle.Request(map[string]interface{}{"request": "sync"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if success.PluginData.Data["echotest"] != "success" {
+		t.Errorf("unexpected plugin data %v", success.PluginData.Data)
+	}
+
+	event, err := handle.Message(map[string]interface{}{"async": true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Plugindata.Data["result"] != "ok" {
+		t.Errorf("unexpected plugin data %v", event.Plugindata.Data)
+	}
+	if event.Jsep["type"] != "answer" {
+		t.Errorf("unexpected jsep %v", event.Jsep)
+	}
+}
+
+func TestServer_PushEvent(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	gateway, session, handle := connect(t, server)
+	defer gateway.Close()
+
+	err := server.PushEvent(session.ID, handle.ID, map[string]interface{}{"echotest": "event"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, ok := nextEvent(t, handle.Events).(*janus.EventMsg)
+	if !ok {
+		t.Fatal("expected an EventMsg")
+	}
+	if event.Plugindata.Plugin != "janus.plugin.echotest" || event.Plugindata.Data["echotest"] != "event" {
+		t.Errorf("unexpected plugin data %v", event.Plugindata)
+	}
+
+	if err := server.ExpireSession(session.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := nextEvent(t, session.Events).(*janus.TimeoutMsg); !ok {
+		t.Error("expected a TimeoutMsg")
+	}
+}
+
+func TestServer_FailNext(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	gateway, err := janus.Connect(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gateway.Close()
+
+	server.FailNext("create", janustest.ErrorUnknown, "Injected failure")
+	_, err = gateway.Create()
+	errMsg, ok := err.(*janus.ErrorMsg)
+	if !ok {
+		t.Fatalf("expected an ErrorMsg, got %v", err)
+	}
+	if errMsg.Err.Code != janustest.ErrorUnknown {
+		t.Errorf("expected error code %d, got %d", janustest.ErrorUnknown, errMsg.Err.Code)
+	}
+	if sessions := server.Sessions(); len(sessions) != 0 {
+		t.Errorf("expected the failed create to leave no session, got %v", sessions)
+	}
+
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatalf("expected only the first create to fail, got %v", err)
+	}
+
+	api, err := admin.NewAdminAPI(server.AdminURL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.FailNext("destroy_session", janustest.ErrorUnknown, "Injected failure")
+	if err := api.DestroySession(session.ID); err == nil {
+		t.Error("expected the scripted destroy_session to fail")
+	}
+	if sessions := server.Sessions(); len(sessions) != 1 || sessions[0] != session.ID {
+		t.Errorf("expected the failed destroy_session to keep session %d, got %v", session.ID, sessions)
+	}
+}
+
+func TestServer_Delay(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	gateway, err := janus.Connect(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gateway.Close()
+
+	server.Delay("info", 200*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := gateway.InfoContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected the delayed request to time out, got %v", err)
+	}
+
+	server.Delay("info", 0)
+	if _, err := gateway.Info(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServer_Disconnect(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	gateway, err := janus.Connect(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gateway.Close()
+	gateway.Reconnect = &janus.ReconnectPolicy{InitialDelay: 10 * time.Millisecond}
+
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.Disconnect()
+
+	select {
+	case result := <-gateway.GetReclaimChan():
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+		if result.Session.ID != session.ID {
+			t.Errorf("expected session %d to be reclaimed, got %d", session.ID, result.Session.ID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for reclaim")
+	}
+
+	if _, err := session.KeepAlive(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServer_Admin(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+	server.AdminSecret = "secret"
+
+	gateway, session, handle := connect(t, server)
+	defer gateway.Close()
+
+	api, err := admin.NewAdminAPI(server.AdminURL, "wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.ListSessions(); err == nil {
+		t.Error("expected an error with the wrong admin secret")
+	}
+
+	api, err = admin.NewAdminAPI(server.AdminURL, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := api.HandleInfo(session.ID, handle.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Plugin != "janus.plugin.echotest" {
+		t.Errorf("unexpected handle info %+v", info)
+	}
+
+	if _, err := api.HandleInfo(session.ID, handle.ID+1); err == nil {
+		t.Error("expected an error for an unknown handle")
+	}
+}
@@ -0,0 +1,88 @@
+package janus
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// KeepAliveError is delivered on Session.Events when a keepalive request
+// sent by the Gateway's keepalive scheduler fails. If Janus no longer knows
+// the session, it is also removed from Gateway.Sessions.
+type KeepAliveError struct {
+	Session uint64
+	Err     error
+}
+
+func (err *KeepAliveError) Error() string {
+	return fmt.Sprintf("keepalive of session %d failed: %s", err.Session, err.Err)
+}
+
+func (err *KeepAliveError) Unwrap() error {
+	return err.Err
+}
+
+// keepAlive sends a keepalive request for the session every
+// Gateway.KeepAliveInterval, randomized by up to +/- Gateway.KeepAliveJitter,
+// until the session is destroyed or the Gateway is closed.
+func (session *Session) keepAlive() {
+	gateway := session.gateway
+
+	for {
+		interval := gateway.KeepAliveInterval
+		if jitter := gateway.keepAliveJitter(); jitter > 0 {
+			interval += time.Duration(rand.Int63n(int64(2*jitter))) - jitter
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-session.done:
+			timer.Stop()
+			return
+		case <-gateway.closed:
+			timer.Stop()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), gateway.KeepAliveInterval)
+		_, err := session.KeepAliveContext(ctx)
+		cancel()
+		if err == nil {
+			continue
+		}
+
+		errMsg, ok := err.(*ErrorMsg)
+		gone := ok && errMsg.Err.Code == errSessionNotFound
+		if gone {
+			gateway.Lock()
+			delete(gateway.Sessions, session.ID)
+			gateway.Unlock()
+		}
+
+		session.events.push(&KeepAliveError{Session: session.ID, Err: err})
+
+		if gone {
+			return
+		}
+	}
+}
+
+// keepAliveJitter returns KeepAliveJitter, clamped to half the interval so
+// that keepalives are never sent back to back.
+func (gateway *Gateway) keepAliveJitter() time.Duration {
+	jitter := gateway.KeepAliveJitter
+	if max := gateway.KeepAliveInterval / 2; jitter > max {
+		jitter = max
+	}
+	return jitter
+}
+
+func (session *Session) stopKeepAlive() {
+	session.doneOnce.Do(func() {
+		if session.done != nil {
+			close(session.done)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package janus
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGateway_KeepAlive(t *testing.T) {
+	var keepalives int32
+	respond := func(req map[string]interface{}) []string {
+		tx := req["transaction"]
+		switch req["janus"] {
+		case "create":
+			return []string{fmt.Sprintf(`{"janus":"success","transaction":"%s","data":{"id":1}}`, tx)}
+		case "keepalive":
+			if atomic.AddInt32(&keepalives, 1) == 2 {
+				return []string{fmt.Sprintf(`{"janus":"error","session_id":1,"transaction":"%s","error":{"code":490,"reason":"Unexpected"}}`, tx)}
+			}
+			return []string{fmt.Sprintf(`{"janus":"ack","session_id":1,"transaction":"%s"}`, tx)}
+		case "destroy":
+			return []string{fmt.Sprintf(`{"janus":"ack","session_id":1,"transaction":"%s"}`, tx)}
+		}
+		t.Errorf("unexpected request %v", req["janus"])
+		return nil
+	}
+
+	gateway := NewGateway(newMemTransport(respond))
+	defer gateway.Close()
+	gateway.KeepAliveInterval = 10 * time.Millisecond
+	gateway.KeepAliveJitter = 5 * time.Millisecond
+
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ev := <-session.Events:
+		var kaErr *KeepAliveError
+		if err, ok := ev.(error); !ok || !errors.As(err, &kaErr) {
+			t.Fatalf("wrong type: KeepAliveError != %T", ev)
+		}
+		if kaErr.Session != session.ID {
+			t.Errorf("sessionID mismatch, expected %d got %d", session.ID, kaErr.Session)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("keepalive error not delivered")
+	}
+
+	if _, err := session.Destroy(); err != nil {
+		t.Fatal(err)
+	}
+	sent := atomic.LoadInt32(&keepalives)
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&keepalives); n != sent {
+		t.Errorf("keepalives sent after destroy: %d", n-sent)
+	}
+}
+
+func TestGateway_KeepAliveJitter(t *testing.T) {
+	gateway := &Gateway{KeepAliveInterval: 10 * time.Second, KeepAliveJitter: time.Minute}
+	if jitter := gateway.keepAliveJitter(); jitter != 5*time.Second {
+		t.Errorf("expected jitter clamped to 5s, got %s", jitter)
+	}
+
+	gateway.KeepAliveJitter = time.Second
+	if jitter := gateway.keepAliveJitter(); jitter != time.Second {
+		t.Errorf("expected jitter 1s, got %s", jitter)
+	}
+}
+
+func TestGateway_KeepAliveSessionNotFound(t *testing.T) {
+	respond := func(req map[string]interface{}) []string {
+		tx := req["transaction"]
+		switch req["janus"] {
+		case "create":
+			return []string{fmt.Sprintf(`{"janus":"success","transaction":"%s","data":{"id":1}}`, tx)}
+		case "keepalive":
+			return []string{fmt.Sprintf(`{"janus":"error","session_id":1,"transaction":"%s","error":{"code":458,"reason":"No such session"}}`, tx)}
+		}
+		t.Errorf("unexpected request %v", req["janus"])
+		return nil
+	}
+
+	gateway := NewGateway(newMemTransport(respond))
+	defer gateway.Close()
+	gateway.KeepAliveInterval = 10 * time.Millisecond
+
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-session.Events:
+	case <-time.After(time.Second):
+		t.Fatal("keepalive error not delivered")
+	}
+
+	gateway.Lock()
+	_, ok := gateway.Sessions[session.ID]
+	gateway.Unlock()
+	if ok {
+		t.Errorf("session %d should have been removed", session.ID)
+	}
+}
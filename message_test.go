@@ -0,0 +1,148 @@
+package janus
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHandle_MessageAsync(t *testing.T) {
+	var acked []string
+	transport := newMemTransport(nil)
+	transport.respond = func(req map[string]interface{}) []string {
+		tx := req["transaction"].(string)
+		switch req["janus"] {
+		case "create":
+			return []string{fmt.Sprintf(`{"janus":"success","transaction":"%s","data":{"id":1}}`, tx)}
+		case "attach":
+			return []string{fmt.Sprintf(`{"janus":"success","session_id":1,"transaction":"%s","data":{"id":2}}`, tx)}
+		case "message":
+			body := req["body"].(map[string]interface{})
+			if body["request"] == "list" {
+				return []string{fmt.Sprintf(`{"janus":"success","session_id":1,"sender":2,"transaction":"%s","plugindata":{"plugin":"janus.plugin.videoroom","data":{"videoroom":"success"}}}`, tx)}
+			}
+			acked = append(acked, tx)
+			resp := []string{fmt.Sprintf(`{"janus":"ack","session_id":1,"transaction":"%s"}`, tx)}
+			if len(acked) == 2 {
+				// answer both messages, in reverse order
+				for i := len(acked) - 1; i >= 0; i-- {
+					resp = append(resp, fmt.Sprintf(`{"janus":"event","session_id":1,"sender":2,"transaction":"%s","plugindata":{"plugin":"janus.plugin.videoroom","data":{"transaction":"%s"}}}`, acked[i], acked[i]))
+				}
+			}
+			return resp
+		}
+		t.Errorf("unexpected request %v", req["janus"])
+		return nil
+	}
+
+	gateway := NewGateway(transport)
+	defer gateway.Close()
+
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle, err := session.Attach("janus.plugin.videoroom")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	configure, err := handle.MessageAsync(map[string]interface{}{"request": "configure"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publish, err := handle.MessageAsync(map[string]interface{}{"request": "publish"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pending := range []*PendingMessage{configure, publish} {
+		reply, err := pending.Reply(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := reply.(*AckMsg); !ok {
+			t.Errorf("wrong type: AckMsg != %T", reply)
+		}
+		event, err := pending.Event(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.Plugindata.Data["transaction"] != pending.Transaction {
+			t.Errorf("event of transaction %v delivered to %s", event.Plugindata.Data["transaction"], pending.Transaction)
+		}
+	}
+
+	success, err := handle.RequestContext(ctx, map[string]interface{}{"request": "list"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if success.PluginData.Plugin != "janus.plugin.videoroom" {
+		t.Errorf("unexpected plugin %s", success.PluginData.Plugin)
+	}
+
+	if n := gateway.InFlightTransactions(); n != 0 {
+		t.Errorf("expected no in-flight transactions, found %d", n)
+	}
+}
+
+func TestPendingMessage_EventWithoutReply(t *testing.T) {
+	transport := newMemTransport(func(req map[string]interface{}) []string {
+		tx := req["transaction"].(string)
+		switch req["janus"] {
+		case "create":
+			return []string{fmt.Sprintf(`{"janus":"success","transaction":"%s","data":{"id":1}}`, tx)}
+		case "attach":
+			return []string{fmt.Sprintf(`{"janus":"success","session_id":1,"transaction":"%s","data":{"id":2}}`, tx)}
+		case "message":
+			if req["body"].(map[string]interface{})["request"] == "list" {
+				return []string{fmt.Sprintf(`{"janus":"success","session_id":1,"sender":2,"transaction":"%s","plugindata":{"plugin":"janus.plugin.videoroom","data":{"videoroom":"success"}}}`, tx)}
+			}
+			return []string{fmt.Sprintf(`{"janus":"error","session_id":1,"transaction":"%s","error":{"code":454,"reason":"No such handle"}}`, tx)}
+		}
+		t.Errorf("unexpected request %v", req["janus"])
+		return nil
+	})
+
+	gateway := NewGateway(transport)
+	defer gateway.Close()
+
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle, err := session.Attach("janus.plugin.videoroom")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Event alone returns the error answering the message
+	pending, err := handle.MessageAsync(map[string]interface{}{"request": "configure"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = pending.Event(ctx)
+	if errMsg, ok := err.(*ErrorMsg); !ok || errMsg.Err.Code != 454 {
+		t.Errorf("expected error 454, got %v", err)
+	}
+
+	// and ErrNoEvent for a synchronous success
+	pending, err = handle.MessageAsync(map[string]interface{}{"request": "list"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pending.Event(ctx); err != ErrNoEvent {
+		t.Errorf("expected %v, got %v", ErrNoEvent, err)
+	}
+
+	if n := gateway.InFlightTransactions(); n != 0 {
+		t.Errorf("expected no in-flight transactions, found %d", n)
+	}
+}
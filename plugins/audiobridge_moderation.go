@@ -0,0 +1,395 @@
+package plugins
+
+import (
+	"github.com/tatsujin1/janus-go"
+)
+
+func (f *AudiobridgeRequestFactory) ExistsRequest(roomID int) *AudiobridgeRoomRequest {
+	return &AudiobridgeRoomRequest{
+		BasePluginRequest: f.make("exists"),
+		RoomID:            roomID,
+	}
+}
+
+// AllowedRequest changes the tokens allowed to join a room. action is one of
+// "enable", "disable", "add" or "remove", allowed is ignored by the first two.
+func (f *AudiobridgeRequestFactory) AllowedRequest(roomID int, secret, action string, allowed []string) *AudiobridgeAllowedRequest {
+	return &AudiobridgeAllowedRequest{
+		BasePluginRequest: f.make("allowed"),
+		RoomID:            roomID,
+		Secret:            secret,
+		AllowedAction:     action,
+		Allowed:           allowed,
+	}
+}
+
+func (f *AudiobridgeRequestFactory) KickRequest(roomID int, secret string, participantID uint64) *AudiobridgeParticipantRequest {
+	return &AudiobridgeParticipantRequest{
+		BasePluginRequest: f.make("kick"),
+		RoomID:            roomID,
+		Secret:            secret,
+		ParticipantID:     participantID,
+	}
+}
+
+func (f *AudiobridgeRequestFactory) KickAllRequest(roomID int, secret string) *AudiobridgeRoomRequest {
+	return &AudiobridgeRoomRequest{
+		BasePluginRequest: f.make("kick_all"),
+		RoomID:            roomID,
+		Secret:            secret,
+	}
+}
+
+// MuteRequest mutes (mute) or unmutes (unmute) a participant.
+func (f *AudiobridgeRequestFactory) MuteRequest(roomID int, secret string, participantID uint64, mute bool) *AudiobridgeParticipantRequest {
+	action := "unmute"
+	if mute {
+		action = "mute"
+	}
+	return &AudiobridgeParticipantRequest{
+		BasePluginRequest: f.make(action),
+		RoomID:            roomID,
+		Secret:            secret,
+		ParticipantID:     participantID,
+	}
+}
+
+// MuteRoomRequest mutes (mute_room) or unmutes (unmute_room) all the
+// participants of a room.
+func (f *AudiobridgeRequestFactory) MuteRoomRequest(roomID int, secret string, mute bool) *AudiobridgeRoomRequest {
+	action := "unmute_room"
+	if mute {
+		action = "mute_room"
+	}
+	return &AudiobridgeRoomRequest{
+		BasePluginRequest: f.make(action),
+		RoomID:            roomID,
+		Secret:            secret,
+	}
+}
+
+func (f *AudiobridgeRequestFactory) ListParticipantsRequest(roomID int) *AudiobridgeRoomRequest {
+	return &AudiobridgeRoomRequest{
+		BasePluginRequest: f.make("listparticipants"),
+		RoomID:            roomID,
+	}
+}
+
+// ResetDecoderRequest resets the decoder of the participant of the handle
+// the request is sent through. It must be sent as a message of the
+// participant's handle, not through MessagePlugin, and is answered by an
+// event.
+func (f *AudiobridgeRequestFactory) ResetDecoderRequest() *BasePluginRequest {
+	request := f.make("resetdecoder")
+	return &request
+}
+
+func (f *AudiobridgeRequestFactory) RtpForwardRequest(roomID int, secret string, forward *AudiobridgeRtpForward) *AudiobridgeRtpForwardRequest {
+	return &AudiobridgeRtpForwardRequest{
+		BasePluginRequest: f.make("rtp_forward"),
+		RoomID:            roomID,
+		Secret:            secret,
+		Forward:           forward,
+	}
+}
+
+func (f *AudiobridgeRequestFactory) StopRtpForwardRequest(roomID int, secret string, streamID uint64) *AudiobridgeStreamRequest {
+	return &AudiobridgeStreamRequest{
+		BasePluginRequest: f.make("stop_rtp_forward"),
+		RoomID:            roomID,
+		Secret:            secret,
+		StreamID:          streamID,
+	}
+}
+
+func (f *AudiobridgeRequestFactory) ListForwardersRequest(roomID int, secret string) *AudiobridgeRoomRequest {
+	return &AudiobridgeRoomRequest{
+		BasePluginRequest: f.make("listforwarders"),
+		RoomID:            roomID,
+		Secret:            secret,
+	}
+}
+
+// PlayFileRequest plays an Opus file in a room, fileID can be empty to have
+// the plugin pick one.
+func (f *AudiobridgeRequestFactory) PlayFileRequest(roomID int, secret, fileID, filename string, loop bool) *AudiobridgePlayFileRequest {
+	return &AudiobridgePlayFileRequest{
+		BasePluginRequest: f.make("play_file"),
+		RoomID:            roomID,
+		Secret:            secret,
+		FileID:            fileID,
+		Filename:          filename,
+		Loop:              loop,
+	}
+}
+
+func (f *AudiobridgeRequestFactory) IsPlayingRequest(roomID int, secret, fileID string) *AudiobridgeFileRequest {
+	return &AudiobridgeFileRequest{
+		BasePluginRequest: f.make("is_playing"),
+		RoomID:            roomID,
+		Secret:            secret,
+		FileID:            fileID,
+	}
+}
+
+func (f *AudiobridgeRequestFactory) StopFileRequest(roomID int, secret, fileID string) *AudiobridgeFileRequest {
+	return &AudiobridgeFileRequest{
+		BasePluginRequest: f.make("stop_file"),
+		RoomID:            roomID,
+		Secret:            secret,
+		FileID:            fileID,
+	}
+}
+
+// EnableRecordingRequest starts or stops recording the mix of a room, in
+// recordFile if not empty.
+func (f *AudiobridgeRequestFactory) EnableRecordingRequest(roomID int, secret string, record bool, recordFile string) *AudiobridgeEnableRecordingRequest {
+	return &AudiobridgeEnableRecordingRequest{
+		BasePluginRequest: f.make("enable_recording"),
+		RoomID:            roomID,
+		Secret:            secret,
+		Record:            record,
+		RecordFile:        recordFile,
+	}
+}
+
+// AudiobridgeRoomRequest is a request addressing a whole room, e.g. exists,
+// kick_all, mute_room or listparticipants. Secret is only sent when set, as
+// the requests reading the state of a room don't need it.
+type AudiobridgeRoomRequest struct {
+	BasePluginRequest
+	RoomID int
+	Secret string
+}
+
+func (r *AudiobridgeRoomRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type AudiobridgeSuccessResponse struct {
+	AudiobridgeResponse
+	RoomID int `json:"room"`
+}
+
+type AudiobridgeExistsResponse struct {
+	AudiobridgeResponse
+	RoomID int  `json:"room"`
+	Exists bool `json:"exists"`
+}
+
+type AudiobridgeAllowedRequest struct {
+	BasePluginRequest
+	RoomID        int
+	Secret        string
+	AllowedAction string
+	Allowed       []string
+}
+
+func (r *AudiobridgeAllowedRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["action"] = r.AllowedAction
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	if len(r.Allowed) > 0 {
+		payload["allowed"] = r.Allowed
+	}
+	return payload
+}
+
+type AudiobridgeAllowedResponse struct {
+	AudiobridgeResponse
+	RoomID  int      `json:"room"`
+	Allowed []string `json:"allowed"`
+}
+
+// AudiobridgeParticipantRequest is a request about a participant of a room.
+type AudiobridgeParticipantRequest struct {
+	BasePluginRequest
+	RoomID        int
+	Secret        string
+	ParticipantID uint64
+}
+
+func (r *AudiobridgeParticipantRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["id"] = r.ParticipantID
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type AudiobridgeParticipantsResponse struct {
+	AudiobridgeResponse
+	RoomID       int                           `json:"room"`
+	Participants []*AudiobridgeParticipantInfo `json:"participants"`
+}
+
+type AudiobridgeRtpForward struct {
+	Group      string `json:"group,omitempty"`
+	Host       string `json:"host"`
+	HostFamily string `json:"host_family,omitempty"`
+	Port       int    `json:"port"`
+	Ssrc       uint32 `json:"ssrc,omitempty"`
+	Codec      string `json:"codec,omitempty"`
+	PT         int    `json:"ptype,omitempty"`
+	SrtpSuite  int    `json:"srtp_suite,omitempty"`
+	SrtpCrypto string `json:"srtp_crypto,omitempty"`
+	AlwaysOn   bool   `json:"always_on,omitempty"`
+}
+
+type AudiobridgeRtpForwardRequest struct {
+	BasePluginRequest
+	RoomID  int
+	Secret  string
+	Forward *AudiobridgeRtpForward
+}
+
+func (r *AudiobridgeRtpForwardRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	forward, _ := janus.StructToMap(r.Forward)
+	mergeMap(payload, forward)
+	return payload
+}
+
+type AudiobridgeRtpForwardResponse struct {
+	AudiobridgeResponse
+	RoomID   int    `json:"room"`
+	Group    string `json:"group"`
+	StreamID uint64 `json:"stream_id"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+}
+
+// AudiobridgeStreamRequest is a request about an RTP forwarder of a room.
+type AudiobridgeStreamRequest struct {
+	BasePluginRequest
+	RoomID   int
+	Secret   string
+	StreamID uint64
+}
+
+func (r *AudiobridgeStreamRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["stream_id"] = r.StreamID
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type AudiobridgeStopRtpForwardResponse struct {
+	AudiobridgeResponse
+	RoomID   int    `json:"room"`
+	StreamID uint64 `json:"stream_id"`
+}
+
+type AudiobridgeForwarder struct {
+	StreamID uint64 `json:"stream_id"`
+	Group    string `json:"group"`
+	IP       string `json:"ip"`
+	Port     int    `json:"port"`
+	Ssrc     uint32 `json:"ssrc"`
+	Codec    string `json:"codec"`
+	PT       int    `json:"ptype"`
+	Srtp     bool   `json:"srtp"`
+	AlwaysOn bool   `json:"always_on"`
+}
+
+type AudiobridgeForwardersResponse struct {
+	AudiobridgeResponse
+	RoomID     int                     `json:"room"`
+	Forwarders []*AudiobridgeForwarder `json:"rtp_forwarders"`
+}
+
+type AudiobridgePlayFileRequest struct {
+	BasePluginRequest
+	RoomID   int
+	Secret   string
+	FileID   string
+	Filename string
+	Loop     bool
+}
+
+func (r *AudiobridgePlayFileRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["filename"] = r.Filename
+	payload["loop"] = r.Loop
+	if r.FileID != "" {
+		payload["file_id"] = r.FileID
+	}
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+// AudiobridgeFileRequest is a request about a file played in a room.
+type AudiobridgeFileRequest struct {
+	BasePluginRequest
+	RoomID int
+	Secret string
+	FileID string
+}
+
+func (r *AudiobridgeFileRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["file_id"] = r.FileID
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type AudiobridgeFileResponse struct {
+	AudiobridgeResponse
+	RoomID int    `json:"room"`
+	FileID string `json:"file_id"`
+}
+
+type AudiobridgeIsPlayingResponse struct {
+	AudiobridgeFileResponse
+	Playing bool `json:"playing"`
+}
+
+type AudiobridgeEnableRecordingRequest struct {
+	BasePluginRequest
+	RoomID     int
+	Secret     string
+	Record     bool
+	RecordFile string
+}
+
+func (r *AudiobridgeEnableRecordingRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["record"] = r.Record
+	if r.RecordFile != "" {
+		payload["record_file"] = r.RecordFile
+	}
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type AudiobridgeEnableRecordingResponse struct {
+	AudiobridgeResponse
+	RoomID int  `json:"room"`
+	Record bool `json:"record"`
+}
@@ -0,0 +1,97 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestAudiobridgeRequestFactory_Mute(t *testing.T) {
+	requestFactory := MakeAudiobridgeRequestFactory("supersecret")
+
+	for mute, action := range map[bool]string{true: "mute", false: "unmute"} {
+		payload := requestFactory.MuteRequest(1234, "test_secret", 42, mute).Payload()
+		if payload["request"] != action || payload["room"] != 1234 || payload["id"] != uint64(42) || payload["secret"] != "test_secret" {
+			t.Errorf("unexpected %s payload %v", action, payload)
+		}
+	}
+
+	for mute, action := range map[bool]string{true: "mute_room", false: "unmute_room"} {
+		payload := requestFactory.MuteRoomRequest(1234, "test_secret", mute).Payload()
+		if payload["request"] != action || payload["room"] != 1234 || payload["secret"] != "test_secret" {
+			t.Errorf("unexpected %s payload %v", action, payload)
+		}
+	}
+}
+
+func TestAudiobridgeModeration_Mute(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	joined := make(chan uint64, 1)
+	requests := make(chan map[string]interface{}, 4)
+	server.HandlePlugin("janus.plugin.audiobridge", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		switch msg.Body["request"] {
+		case "join":
+			joined <- msg.Session
+			return event(map[string]interface{}{"audiobridge": "joined", "room": 1234, "id": 42, "participants": []interface{}{}}, nil)
+		case "mute", "unmute", "mute_room", "unmute_room":
+			requests <- msg.Body
+			return &janustest.PluginResponse{Data: map[string]interface{}{"audiobridge": "success", "room": 1234}}
+		}
+		return nil
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.audiobridge")
+	defer gateway.Close()
+	moderatorGateway, moderator := newTestHandle(t, server, "janus.plugin.audiobridge")
+	defer moderatorGateway.Close()
+
+	participant := NewAudiobridgeParticipant(handle)
+	if _, err := participant.Join(&AudiobridgeJoin{Room: 1234, Display: "bob"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	sessionID := <-joined
+
+	requestFactory := MakeAudiobridgeRequestFactory("supersecret")
+	for _, mute := range []bool{true, false} {
+		success, err := moderator.Request(requestFactory.MuteRequest(1234, "test_secret", participant.ID, mute).Payload())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if success.PluginData.Data["audiobridge"] != "success" {
+			t.Errorf("unexpected response %v", success.PluginData.Data)
+		}
+		if body := <-requests; body["id"] != 42.0 {
+			t.Errorf("unexpected mute request %v", body)
+		}
+
+		// the participants of the room are notified of the change
+		err = server.PushEvent(sessionID, handle.ID, map[string]interface{}{
+			"audiobridge":  "event",
+			"room":         1234,
+			"participants": []interface{}{map[string]interface{}{"id": 42, "display": "bob", "setup": true, "muted": mute}},
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ev, err := ParseAudiobridgeEvent(nextEvent(t, handle))
+		if err != nil {
+			t.Fatal(err)
+		}
+		list, ok := ev.(*AudiobridgeParticipantsEvent)
+		if !ok || len(list.Participants) != 1 || list.Participants[0].ID != participant.ID || list.Participants[0].Muted != mute {
+			t.Errorf("unexpected participants event %+v", ev)
+		}
+	}
+
+	for _, mute := range []bool{true, false} {
+		if _, err := moderator.Request(requestFactory.MuteRoomRequest(1234, "test_secret", mute).Payload()); err != nil {
+			t.Fatal(err)
+		}
+		expected := map[bool]string{true: "mute_room", false: "unmute_room"}[mute]
+		if body := <-requests; body["request"] != expected {
+			t.Errorf("expected %s, got %v", expected, body)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+// AudiobridgeParticipant is a participant of an audiobridge room, on top of
+// a handle attached to janus.plugin.audiobridge.
+type AudiobridgeParticipant struct {
+	Handle *janus.Handle
+
+	// Room and ID are set once the participant joined a room.
+	Room int
+	ID   uint64
+}
+
+func NewAudiobridgeParticipant(handle *janus.Handle) *AudiobridgeParticipant {
+	return &AudiobridgeParticipant{Handle: handle}
+}
+
+type AudiobridgeJoin struct {
+	Room               int    `json:"room"`
+	ID                 uint64 `json:"id,omitempty"`
+	Group              string `json:"group,omitempty"`
+	Pin                string `json:"pin,omitempty"`
+	Display            string `json:"display,omitempty"`
+	Token              string `json:"token,omitempty"`
+	Muted              bool   `json:"muted,omitempty"`
+	Codec              string `json:"codec,omitempty"`
+	Prebuffer          int    `json:"prebuffer,omitempty"`
+	Bitrate            int    `json:"bitrate,omitempty"`
+	Quality            int    `json:"quality,omitempty"`
+	ExpectedLoss       int    `json:"expected_loss,omitempty"`
+	Volume             int    `json:"volume,omitempty"`
+	SpatialPosition    *int   `json:"spatial_position,omitempty"`
+	AudioLevelAverage  int    `json:"audio_level_average,omitempty"`
+	AudioActivePackets int    `json:"audio_active_packets,omitempty"`
+	Record             bool   `json:"record,omitempty"`
+	Filename           string `json:"filename,omitempty"`
+}
+
+// AudiobridgeConfigure holds the settings of a configure request. nil and
+// empty fields are left unchanged.
+type AudiobridgeConfigure struct {
+	Muted           *bool  `json:"muted,omitempty"`
+	Display         string `json:"display,omitempty"`
+	Prebuffer       int    `json:"prebuffer,omitempty"`
+	Bitrate         int    `json:"bitrate,omitempty"`
+	Quality         int    `json:"quality,omitempty"`
+	ExpectedLoss    int    `json:"expected_loss,omitempty"`
+	Volume          int    `json:"volume,omitempty"`
+	SpatialPosition *int   `json:"spatial_position,omitempty"`
+	Record          *bool  `json:"record,omitempty"`
+	Filename        string `json:"filename,omitempty"`
+	Group           string `json:"group,omitempty"`
+}
+
+type AudiobridgeParticipantInfo struct {
+	ID              uint64 `json:"id"`
+	Display         string `json:"display"`
+	Setup           bool   `json:"setup"`
+	Muted           bool   `json:"muted"`
+	Talking         bool   `json:"talking"`
+	SpatialPosition int    `json:"spatial_position"`
+}
+
+type AudiobridgeJoinedEvent struct {
+	AudiobridgeResponse
+	Room         int                           `json:"room"`
+	ID           uint64                        `json:"id"`
+	Participants []*AudiobridgeParticipantInfo `json:"participants"`
+	Jsep         map[string]interface{}        `json:"-"`
+}
+
+// AudiobridgeRoomChangedEvent answers a changeroom request, listing the
+// participants of the new room.
+type AudiobridgeRoomChangedEvent struct {
+	AudiobridgeResponse
+	Room         int                           `json:"room"`
+	ID           uint64                        `json:"id"`
+	Participants []*AudiobridgeParticipantInfo `json:"participants"`
+}
+
+type AudiobridgeConfiguredEvent struct {
+	AudiobridgeResponse
+	Room   int                    `json:"room"`
+	Result string                 `json:"result"`
+	Jsep   map[string]interface{} `json:"-"`
+}
+
+// AudiobridgeParticipantsEvent notifies that participants joined the room or
+// changed their settings (e.g. muted).
+type AudiobridgeParticipantsEvent struct {
+	AudiobridgeResponse
+	Room         int                           `json:"room"`
+	Participants []*AudiobridgeParticipantInfo `json:"participants"`
+}
+
+// AudiobridgeLeavingEvent notifies that a participant left the room.
+type AudiobridgeLeavingEvent struct {
+	AudiobridgeResponse
+	Room int    `json:"room"`
+	ID   uint64 `json:"leaving"`
+}
+
+type AudiobridgeLeftEvent struct {
+	AudiobridgeResponse
+	Room int    `json:"room"`
+	ID   uint64 `json:"id"`
+}
+
+// AudiobridgeTalkingEvent notifies that a participant started (talking) or
+// stopped (stopped-talking) talking, in rooms with audiolevel_event enabled.
+type AudiobridgeTalkingEvent struct {
+	AudiobridgeResponse
+	Room    int    `json:"room"`
+	ID      uint64 `json:"id"`
+	Talking bool   `json:"-"`
+}
+
+// ParseAudiobridgeEvent decodes the plugin data of an event received from
+// the audiobridge plugin to one of the Audiobridge*Event types, or to a
+// *AudiobridgeErrorResponse. Unknown events are returned as the raw plugin
+// data.
+func ParseAudiobridgeEvent(event *janus.EventMsg) (interface{}, error) {
+	data := event.Plugindata.Data
+
+	var v interface{}
+	switch data["audiobridge"] {
+	case "joined":
+		v = &AudiobridgeJoinedEvent{Jsep: event.Jsep}
+	case "roomchanged":
+		v = &AudiobridgeRoomChangedEvent{}
+	case "left":
+		v = &AudiobridgeLeftEvent{}
+	case "talking", "stopped-talking":
+		v = &AudiobridgeTalkingEvent{Talking: data["audiobridge"] == "talking"}
+	case "event":
+		if _, ok := data["error"]; ok {
+			v = &AudiobridgeErrorResponse{}
+		} else if _, ok := data["result"]; ok {
+			v = &AudiobridgeConfiguredEvent{Jsep: event.Jsep}
+		} else if _, ok := data["participants"]; ok {
+			v = &AudiobridgeParticipantsEvent{}
+		} else if _, ok := data["leaving"]; ok {
+			v = &AudiobridgeLeavingEvent{}
+		}
+	}
+	if v == nil {
+		return data, nil
+	}
+
+	if err := decodePluginData(data, v); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal audiobridge event : %w", err)
+	}
+	return v, nil
+}
+
+// audiobridgeMessage sends a request to the audiobridge plugin through
+// handle, and parses the event answering it.
+func audiobridgeMessage(ctx context.Context, handle *janus.Handle, request string, body interface{}, jsep map[string]interface{}) (interface{}, error) {
+	return pluginMessage(ctx, handle, request, body, jsep, &AudiobridgeErrorResponse{}, ParseAudiobridgeEvent)
+}
+
+// Join joins a room. offer can carry the SDP offer of the participant, or be
+// nil to negotiate later with Configure.
+// On success, the joined event listing the other participants will be
+// returned and error will be nil.
+func (p *AudiobridgeParticipant) Join(join *AudiobridgeJoin, offer map[string]interface{}) (*AudiobridgeJoinedEvent, error) {
+	return p.JoinContext(context.Background(), join, offer)
+}
+
+// JoinContext is like Join, but gives up waiting for the response when ctx
+// is done.
+func (p *AudiobridgeParticipant) JoinContext(ctx context.Context, join *AudiobridgeJoin, offer map[string]interface{}) (*AudiobridgeJoinedEvent, error) {
+	event, err := audiobridgeMessage(ctx, p.Handle, "join", join, offer)
+	if err != nil {
+		return nil, err
+	}
+	joined, ok := event.(*AudiobridgeJoinedEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to audiobridge join: %v", event)
+	}
+
+	p.Room = joined.Room
+	p.ID = joined.ID
+	return joined, nil
+}
+
+// Configure changes the settings of the participant, e.g. to mute it or
+// change its volume. jsep can carry an SDP offer to (re)negotiate the
+// PeerConnection, the answer of the plugin is in the Jsep field of the
+// returned configured event.
+func (p *AudiobridgeParticipant) Configure(configure *AudiobridgeConfigure, jsep map[string]interface{}) (*AudiobridgeConfiguredEvent, error) {
+	return p.ConfigureContext(context.Background(), configure, jsep)
+}
+
+// ConfigureContext is like Configure, but gives up waiting for the response
+// when ctx is done.
+func (p *AudiobridgeParticipant) ConfigureContext(ctx context.Context, configure *AudiobridgeConfigure, jsep map[string]interface{}) (*AudiobridgeConfiguredEvent, error) {
+	if configure == nil {
+		configure = &AudiobridgeConfigure{}
+	}
+
+	event, err := audiobridgeMessage(ctx, p.Handle, "configure", configure, jsep)
+	if err != nil {
+		return nil, err
+	}
+	configured, ok := event.(*AudiobridgeConfiguredEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to audiobridge configure: %v", event)
+	}
+	return configured, nil
+}
+
+// ChangeRoom moves the participant to another room, keeping its
+// PeerConnection.
+// On success, the roomchanged event listing the participants of the new
+// room will be returned and error will be nil.
+func (p *AudiobridgeParticipant) ChangeRoom(join *AudiobridgeJoin) (*AudiobridgeRoomChangedEvent, error) {
+	return p.ChangeRoomContext(context.Background(), join)
+}
+
+// ChangeRoomContext is like ChangeRoom, but gives up waiting for the response
+// when ctx is done.
+func (p *AudiobridgeParticipant) ChangeRoomContext(ctx context.Context, join *AudiobridgeJoin) (*AudiobridgeRoomChangedEvent, error) {
+	event, err := audiobridgeMessage(ctx, p.Handle, "changeroom", join, nil)
+	if err != nil {
+		return nil, err
+	}
+	changed, ok := event.(*AudiobridgeRoomChangedEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to audiobridge changeroom: %v", event)
+	}
+
+	p.Room = changed.Room
+	p.ID = changed.ID
+	return changed, nil
+}
+
+// Leave leaves the room.
+// On success, error will be nil.
+func (p *AudiobridgeParticipant) Leave() error {
+	return p.LeaveContext(context.Background())
+}
+
+// LeaveContext is like Leave, but gives up waiting for the response when ctx
+// is done.
+func (p *AudiobridgeParticipant) LeaveContext(ctx context.Context) error {
+	event, err := audiobridgeMessage(ctx, p.Handle, "leave", nil, nil)
+	if err != nil {
+		return err
+	}
+	if _, ok := event.(*AudiobridgeLeftEvent); !ok {
+		return fmt.Errorf("unexpected response to audiobridge leave: %v", event)
+	}
+
+	p.Room = 0
+	p.ID = 0
+	return nil
+}
@@ -0,0 +1,166 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestAudiobridgeParticipant(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	answer := map[string]interface{}{"type": "answer", "sdp": "v=0"}
+	participants := []interface{}{
+		map[string]interface{}{"id": 7, "display": "alice", "setup": true, "muted": false},
+	}
+	server.HandlePlugin("janus.plugin.audiobridge", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		switch msg.Body["request"] {
+		case "join":
+			if msg.Body["display"] != "bob" || msg.Body["muted"] != true || msg.Body["token"] != "abc" {
+				t.Errorf("unexpected join %v", msg.Body)
+			}
+			return event(map[string]interface{}{
+				"audiobridge":  "joined",
+				"room":         1234,
+				"id":           42,
+				"participants": participants,
+			}, nil)
+		case "configure":
+			if msg.Body["muted"] != false || msg.Body["volume"] != 150.0 || msg.Body["quality"] != 8.0 {
+				t.Errorf("unexpected configure %v", msg.Body)
+			}
+			var jsep map[string]interface{}
+			if msg.Jsep != nil {
+				jsep = answer
+			}
+			return event(map[string]interface{}{"audiobridge": "event", "room": 1234, "result": "ok"}, jsep)
+		case "changeroom":
+			return event(map[string]interface{}{
+				"audiobridge":  "roomchanged",
+				"room":         5678,
+				"id":           43,
+				"participants": []interface{}{},
+			}, nil)
+		case "leave":
+			return event(map[string]interface{}{"audiobridge": "left", "room": 5678, "id": 43}, nil)
+		}
+		return nil
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.audiobridge")
+	defer gateway.Close()
+
+	participant := NewAudiobridgeParticipant(handle)
+	joined, err := participant.Join(&AudiobridgeJoin{Room: 1234, Display: "bob", Token: "abc", Muted: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if participant.Room != 1234 || participant.ID != 42 {
+		t.Errorf("unexpected participant state %+v", participant)
+	}
+	if len(joined.Participants) != 1 || joined.Participants[0].Display != "alice" || !joined.Participants[0].Setup {
+		t.Errorf("unexpected participants %v", joined.Participants)
+	}
+
+	muted := false
+	offer := map[string]interface{}{"type": "offer", "sdp": "v=0"}
+	configured, err := participant.Configure(&AudiobridgeConfigure{Muted: &muted, Volume: 150, Quality: 8}, offer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configured.Jsep["type"] != "answer" {
+		t.Errorf("expected an answer, got %v", configured.Jsep)
+	}
+
+	changed, err := participant.ChangeRoom(&AudiobridgeJoin{Room: 5678, Display: "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.Room != 5678 || participant.Room != 5678 || participant.ID != 43 {
+		t.Errorf("unexpected participant state after changeroom %+v", participant)
+	}
+
+	if err := participant.Leave(); err != nil {
+		t.Error(err)
+	}
+	if participant.Room != 0 {
+		t.Errorf("unexpected participant state after leave %+v", participant)
+	}
+}
+
+func TestAudiobridgeParticipant_NilBody(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	server.HandlePlugin("janus.plugin.audiobridge", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		if msg.Body["request"] != "changeroom" || len(msg.Body) != 1 {
+			t.Errorf("unexpected changeroom %v", msg.Body)
+		}
+		return event(map[string]interface{}{"audiobridge": "roomchanged", "room": 5678, "id": 43}, nil)
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.audiobridge")
+	defer gateway.Close()
+
+	// a nil request struct is sent as a request without parameters
+	participant := NewAudiobridgeParticipant(handle)
+	if _, err := participant.ChangeRoom(nil); err != nil {
+		t.Fatal(err)
+	}
+	if participant.Room != 5678 {
+		t.Errorf("unexpected participant state after changeroom %+v", participant)
+	}
+}
+
+func TestParseAudiobridgeEvent(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.audiobridge")
+	defer gateway.Close()
+
+	push := func(data map[string]interface{}) interface{} {
+		if err := server.PushEvent(server.Sessions()[0], handle.ID, data, nil); err != nil {
+			t.Fatal(err)
+		}
+		event, err := ParseAudiobridgeEvent(nextEvent(t, handle))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+
+	list, ok := push(map[string]interface{}{
+		"audiobridge":  "event",
+		"room":         1234,
+		"participants": []interface{}{map[string]interface{}{"id": 8, "display": "carol", "muted": true}},
+	}).(*AudiobridgeParticipantsEvent)
+	if !ok || len(list.Participants) != 1 || !list.Participants[0].Muted {
+		t.Errorf("unexpected participants event %+v", list)
+	}
+
+	leaving, ok := push(map[string]interface{}{"audiobridge": "event", "room": 1234, "leaving": 8}).(*AudiobridgeLeavingEvent)
+	if !ok || leaving.ID != 8 {
+		t.Errorf("unexpected leaving event %+v", leaving)
+	}
+
+	talking, ok := push(map[string]interface{}{"audiobridge": "talking", "room": 1234, "id": 8}).(*AudiobridgeTalkingEvent)
+	if !ok || !talking.Talking || talking.ID != 8 {
+		t.Errorf("unexpected talking event %+v", talking)
+	}
+
+	stopped, ok := push(map[string]interface{}{"audiobridge": "stopped-talking", "room": 1234, "id": 8}).(*AudiobridgeTalkingEvent)
+	if !ok || stopped.Talking {
+		t.Errorf("unexpected stopped-talking event %+v", stopped)
+	}
+
+	changed, ok := push(map[string]interface{}{"audiobridge": "roomchanged", "room": 5678, "id": 8, "participants": []interface{}{}}).(*AudiobridgeRoomChangedEvent)
+	if !ok || changed.Room != 5678 {
+		t.Errorf("unexpected roomchanged event %+v", changed)
+	}
+
+	if _, ok := push(map[string]interface{}{"audiobridge": "event", "error_code": 485, "error": "No such room"}).(*AudiobridgeErrorResponse); !ok {
+		t.Error("expected an AudiobridgeErrorResponse")
+	}
+}
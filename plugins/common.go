@@ -1,5 +1,12 @@
 package plugins
 
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/tatsujin1/janus-go"
+)
+
 type PluginRequest interface {
 	PluginName() string
 	ActionName() string
@@ -66,6 +73,23 @@ var ResponseTypeMap = map[string]map[string]func() interface{}{
 		"create":  func() interface{} { return &AudiobridgeCreateResponse{} },
 		"edit":    func() interface{} { return &AudiobridgeEditResponse{} },
 		"destroy": func() interface{} { return &AudiobridgeDestroyResponse{} },
+
+		"exists":           func() interface{} { return &AudiobridgeExistsResponse{} },
+		"allowed":          func() interface{} { return &AudiobridgeAllowedResponse{} },
+		"kick":             func() interface{} { return &AudiobridgeSuccessResponse{} },
+		"kick_all":         func() interface{} { return &AudiobridgeSuccessResponse{} },
+		"mute":             func() interface{} { return &AudiobridgeSuccessResponse{} },
+		"unmute":           func() interface{} { return &AudiobridgeSuccessResponse{} },
+		"mute_room":        func() interface{} { return &AudiobridgeSuccessResponse{} },
+		"unmute_room":      func() interface{} { return &AudiobridgeSuccessResponse{} },
+		"listparticipants": func() interface{} { return &AudiobridgeParticipantsResponse{} },
+		"rtp_forward":      func() interface{} { return &AudiobridgeRtpForwardResponse{} },
+		"stop_rtp_forward": func() interface{} { return &AudiobridgeStopRtpForwardResponse{} },
+		"listforwarders":   func() interface{} { return &AudiobridgeForwardersResponse{} },
+		"play_file":        func() interface{} { return &AudiobridgeFileResponse{} },
+		"is_playing":       func() interface{} { return &AudiobridgeIsPlayingResponse{} },
+		"stop_file":        func() interface{} { return &AudiobridgeFileResponse{} },
+		"enable_recording": func() interface{} { return &AudiobridgeEnableRecordingResponse{} },
 	},
 	"janus.plugin.videoroom": {
 		"error":   func() interface{} { return &VideoroomErrorResponse{} },
@@ -73,6 +97,27 @@ var ResponseTypeMap = map[string]map[string]func() interface{}{
 		"create":  func() interface{} { return &VideoroomCreateResponse{} },
 		"edit":    func() interface{} { return &VideoroomEditResponse{} },
 		"destroy": func() interface{} { return &VideoroomDestroyResponse{} },
+
+		"exists":           func() interface{} { return &VideoroomExistsResponse{} },
+		"allowed":          func() interface{} { return &VideoroomAllowedResponse{} },
+		"kick":             func() interface{} { return &VideoroomSuccessResponse{} },
+		"moderate":         func() interface{} { return &VideoroomSuccessResponse{} },
+		"listparticipants": func() interface{} { return &VideoroomParticipantsResponse{} },
+		"listforwarders":   func() interface{} { return &VideoroomForwardersResponse{} },
+		"rtp_forward":      func() interface{} { return &VideoroomRtpForwardResponse{} },
+		"stop_rtp_forward": func() interface{} { return &VideoroomStopRtpForwardResponse{} },
+		"enable_recording": func() interface{} { return &VideoroomEnableRecordingResponse{} },
+	},
+	"janus.plugin.streaming": {
+		"error":     func() interface{} { return &StreamingErrorResponse{} },
+		"list":      func() interface{} { return &StreamingListResponse{} },
+		"info":      func() interface{} { return &StreamingInfoResponse{} },
+		"create":    func() interface{} { return &StreamingCreateResponse{} },
+		"edit":      func() interface{} { return &StreamingEditResponse{} },
+		"destroy":   func() interface{} { return &StreamingDestroyResponse{} },
+		"enable":    func() interface{} { return &StreamingOkResponse{} },
+		"disable":   func() interface{} { return &StreamingOkResponse{} },
+		"recording": func() interface{} { return &StreamingOkResponse{} },
 	},
 	"janus.plugin.textroom": {
 		"error":   func() interface{} { return &TextroomErrorResponse{} },
@@ -88,3 +133,82 @@ func mergeMap(a, b map[string]interface{}) {
 		a[k] = v
 	}
 }
+
+func decodePluginData(data map[string]interface{}, v interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// handleMessage sends a request to the plugin a handle is attached to, and
+// waits for the plugin event answering it. An error reported by the plugin
+// is decoded into pluginErr and returned.
+func handleMessage(ctx context.Context, handle *janus.Handle, body, jsep map[string]interface{}, pluginErr error) (*janus.EventMsg, error) {
+	var j interface{}
+	if jsep != nil {
+		j = jsep
+	}
+
+	event, err := handle.MessageContext(ctx, body, j)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := event.Plugindata.Data["error"]; ok {
+		if err := decodePluginData(event.Plugindata.Data, pluginErr); err != nil {
+			return nil, err
+		}
+		return nil, pluginErr
+	}
+	return event, nil
+}
+
+// pluginMessage sends a request with the fields of body to the plugin a
+// handle is attached to, and decodes the event answering it with parse.
+func pluginMessage(ctx context.Context, handle *janus.Handle, request string, body interface{}, jsep map[string]interface{}, pluginErr error, parse func(*janus.EventMsg) (interface{}, error)) (interface{}, error) {
+	payload, err := requestPayload(request, body)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := handleMessage(ctx, handle, payload, jsep, pluginErr)
+	if err != nil {
+		return nil, err
+	}
+	return parse(event)
+}
+
+// requestPayload builds the body of a plugin request from the parameters in
+// body, which can be nil.
+func requestPayload(request string, body interface{}) (map[string]interface{}, error) {
+	payload := map[string]interface{}{}
+	if body != nil {
+		m, err := janus.StructToMap(body)
+		if err != nil {
+			return nil, err
+		}
+		// A nil pointer to a request struct is a request without parameters.
+		if m != nil {
+			payload = m
+		}
+	}
+	payload["request"] = request
+	return payload, nil
+}
+
+// handleRequest is like handleMessage, for the requests the plugin answers
+// synchronously.
+func handleRequest(ctx context.Context, handle *janus.Handle, body map[string]interface{}, pluginErr error) (map[string]interface{}, error) {
+	success, err := handle.RequestContext(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := success.PluginData.Data["error"]; ok {
+		if err := decodePluginData(success.PluginData.Data, pluginErr); err != nil {
+			return nil, err
+		}
+		return nil, pluginErr
+	}
+	return success.PluginData.Data, nil
+}
@@ -0,0 +1,43 @@
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tatsujin1/janus-go"
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func newTestHandle(t *testing.T, server *janustest.Server, plugin string) (*janus.Gateway, *janus.Handle) {
+	gateway, err := janus.Connect(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle, err := session.Attach(plugin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gateway, handle
+}
+
+func nextEvent(t *testing.T, handle *janus.Handle) *janus.EventMsg {
+	select {
+	case msg := <-handle.Events:
+		event, ok := msg.(*janus.EventMsg)
+		if !ok {
+			t.Fatalf("expected an EventMsg, got %v", msg)
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+func event(data map[string]interface{}, jsep map[string]interface{}) *janustest.PluginResponse {
+	return &janustest.PluginResponse{Data: data, Jsep: jsep, Async: true}
+}
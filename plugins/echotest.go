@@ -0,0 +1,115 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+type EchotestResponse struct {
+	Echotest string `json:"echotest"`
+}
+
+type EchotestErrorResponse struct {
+	EchotestResponse
+	PluginError
+}
+
+func (err *EchotestErrorResponse) Error() string {
+	return err.PluginError.Error()
+}
+
+// EchotestClient echoes a PeerConnection back to its sender, on top of a
+// handle attached to janus.plugin.echotest.
+type EchotestClient struct {
+	Handle *janus.Handle
+}
+
+func NewEchotestClient(handle *janus.Handle) *EchotestClient {
+	return &EchotestClient{Handle: handle}
+}
+
+// EchotestConfigure holds the settings of the echo. Substream and Temporal
+// select the simulcast layers, SpatialLayer and TemporalLayer the SVC layers
+// sent back. nil and empty fields are left unchanged.
+type EchotestConfigure struct {
+	Audio         *bool  `json:"audio,omitempty"`
+	Video         *bool  `json:"video,omitempty"`
+	Bitrate       int    `json:"bitrate,omitempty"`
+	Record        *bool  `json:"record,omitempty"`
+	Filename      string `json:"filename,omitempty"`
+	Substream     *int   `json:"substream,omitempty"`
+	Temporal      *int   `json:"temporal,omitempty"`
+	Fallback      int    `json:"fallback,omitempty"`
+	SpatialLayer  *int   `json:"spatial_layer,omitempty"`
+	TemporalLayer *int   `json:"temporal_layer,omitempty"`
+	VideoCodec    string `json:"videocodec,omitempty"`
+	VideoProfile  string `json:"videoprofile,omitempty"`
+	OpusRed       bool   `json:"opusred,omitempty"`
+}
+
+// EchotestResultEvent answers a request with the result "ok", with the SDP
+// answer of the plugin in Jsep when the request carried an offer. The result
+// "done" notifies that the PeerConnection was closed.
+type EchotestResultEvent struct {
+	EchotestResponse
+	Result string                 `json:"result"`
+	Jsep   map[string]interface{} `json:"-"`
+}
+
+// ParseEchotestEvent decodes the plugin data of an event received from the
+// echotest plugin to a *EchotestResultEvent, or to a *EchotestErrorResponse.
+// Unknown events are returned as the raw plugin data.
+func ParseEchotestEvent(event *janus.EventMsg) (interface{}, error) {
+	data := event.Plugindata.Data
+
+	var v interface{}
+	if _, ok := data["error"]; ok {
+		v = &EchotestErrorResponse{}
+	} else if _, ok := data["result"]; ok {
+		v = &EchotestResultEvent{Jsep: event.Jsep}
+	}
+	if v == nil {
+		return data, nil
+	}
+
+	if err := decodePluginData(data, v); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal echotest event : %w", err)
+	}
+	return v, nil
+}
+
+// Configure changes the settings of the echo. jsep can carry an SDP offer to
+// (re)negotiate the PeerConnection, the answer of the plugin is in the Jsep
+// field of the returned result event.
+func (c *EchotestClient) Configure(configure *EchotestConfigure, jsep map[string]interface{}) (*EchotestResultEvent, error) {
+	return c.ConfigureContext(context.Background(), configure, jsep)
+}
+
+// ConfigureContext is like Configure, but gives up waiting for the response
+// when ctx is done.
+func (c *EchotestClient) ConfigureContext(ctx context.Context, configure *EchotestConfigure, jsep map[string]interface{}) (*EchotestResultEvent, error) {
+	// The echotest plugin takes the settings alone, without a request name.
+	if configure == nil {
+		configure = &EchotestConfigure{}
+	}
+	body, err := janus.StructToMap(configure)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := handleMessage(ctx, c.Handle, body, jsep, &EchotestErrorResponse{})
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := ParseEchotestEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := parsed.(*EchotestResultEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to echotest configure: %v", parsed)
+	}
+	return result, nil
+}
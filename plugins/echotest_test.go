@@ -0,0 +1,52 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestEchotestClient(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	server.HandlePlugin("janus.plugin.echotest", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		if _, ok := msg.Body["request"]; ok {
+			t.Errorf("unexpected request name in %v", msg.Body)
+		}
+		if msg.Body["bitrate"] == 1.0 {
+			return event(map[string]interface{}{"echotest": "event", "error_code": 412, "error": "Invalid bitrate"}, nil)
+		}
+		var jsep map[string]interface{}
+		if msg.Jsep != nil {
+			jsep = map[string]interface{}{"type": "answer", "sdp": "v=0"}
+		}
+		return event(map[string]interface{}{"echotest": "event", "result": "ok"}, jsep)
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.echotest")
+	defer gateway.Close()
+
+	client := NewEchotestClient(handle)
+	audio, video := true, true
+	result, err := client.Configure(&EchotestConfigure{Audio: &audio, Video: &video}, map[string]interface{}{"type": "offer", "sdp": "v=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Result != "ok" || result.Jsep["type"] != "answer" {
+		t.Errorf("unexpected configure response %+v", result)
+	}
+
+	substream := 0
+	if _, err := client.Configure(&EchotestConfigure{Bitrate: 128000, Substream: &substream}, nil); err != nil {
+		t.Error(err)
+	}
+	if _, err := client.Configure(nil, nil); err != nil {
+		t.Error(err)
+	}
+
+	_, err = client.Configure(&EchotestConfigure{Bitrate: 1}, nil)
+	if perr, ok := err.(*EchotestErrorResponse); !ok || perr.Code != 412 {
+		t.Errorf("expected an EchotestErrorResponse, got %v", err)
+	}
+}
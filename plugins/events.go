@@ -0,0 +1,68 @@
+package plugins
+
+import (
+	"fmt"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+// EventDecoder decodes the plugin data of an event to a typed event.
+type EventDecoder func(event *janus.EventMsg) (interface{}, error)
+
+// EventDecoderMap maps plugin names to the decoders of their events, used by
+// DecodeEvent. Applications can add the decoders of their own plugins, or
+// replace the ones of this package, before decoding events.
+var EventDecoderMap = map[string]EventDecoder{
+	"janus.plugin.audiobridge": ParseAudiobridgeEvent,
+	"janus.plugin.echotest":    ParseEchotestEvent,
+	"janus.plugin.nosip":       ParseNosipEvent,
+	"janus.plugin.recordplay":  ParseRecordplayEvent,
+	"janus.plugin.sip":         ParseSipEvent,
+	"janus.plugin.streaming":   ParseStreamingEvent,
+	"janus.plugin.textroom":    ParseTextroomEvent,
+	"janus.plugin.videocall":   ParseVideocallEvent,
+	"janus.plugin.videoroom":   ParseVideoroomEvent,
+	"janus.plugin.voicemail":   ParseVoicemailEvent,
+}
+
+// DecodeEvent decodes the plugin data of an event with the decoder of the
+// plugin which sent it. The events of plugins without decoder are returned
+// as the raw plugin data.
+func DecodeEvent(event *janus.EventMsg) (interface{}, error) {
+	decode, ok := EventDecoderMap[event.Plugindata.Plugin]
+	if !ok {
+		return event.Plugindata.Data, nil
+	}
+	return decode(event)
+}
+
+// NewEventDecoder returns a decoder for the events of a plugin told apart by
+// their discriminator, the value of the key named key in the plugin data
+// (e.g. "joined" for {"videoroom": "joined"}). types maps discriminators to
+// the types to decode to, and "error" to the type of the errors reported by
+// the plugin. Events with other discriminators are returned as the raw
+// plugin data.
+func NewEventDecoder(key string, types map[string]func() interface{}) EventDecoder {
+	return func(event *janus.EventMsg) (interface{}, error) {
+		return decodeDiscriminated(key, types, event.Plugindata.Data)
+	}
+}
+
+// decodeDiscriminated decodes plugin data like the decoders returned by
+// NewEventDecoder.
+func decodeDiscriminated(key string, types map[string]func() interface{}, data map[string]interface{}) (interface{}, error) {
+	discriminator, _ := data[key].(string)
+	if _, ok := data["error"]; ok {
+		discriminator = "error"
+	}
+	typeFunc, ok := types[discriminator]
+	if !ok {
+		return data, nil
+	}
+
+	v := typeFunc()
+	if err := decodePluginData(data, v); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal %s event : %w", key, err)
+	}
+	return v, nil
+}
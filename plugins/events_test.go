@@ -0,0 +1,75 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+func pluginEvent(plugin string, data map[string]interface{}) *janus.EventMsg {
+	return &janus.EventMsg{Plugindata: janus.PluginData{Plugin: plugin, Data: data}}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	event, err := DecodeEvent(pluginEvent("janus.plugin.videoroom", map[string]interface{}{
+		"videoroom": "event",
+		"room":      1234.0,
+		"leaving":   42.0,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaving, ok := event.(*VideoroomLeavingEvent); !ok || leaving.ID != 42 {
+		t.Errorf("unexpected videoroom event %+v", event)
+	}
+
+	event, err = DecodeEvent(pluginEvent("janus.plugin.textroom", map[string]interface{}{"textroom": "error", "error_code": 417.0, "error": "No such room"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perr, ok := event.(*TextroomErrorResponse); !ok || perr.Code != 417 {
+		t.Errorf("unexpected textroom event %+v", event)
+	}
+
+	data := map[string]interface{}{"unknown": "event"}
+	event, err = DecodeEvent(pluginEvent("janus.plugin.unknown", data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := event.(map[string]interface{}); !ok || m["unknown"] != "event" {
+		t.Errorf("expected the raw plugin data, got %+v", event)
+	}
+}
+
+type customEvent struct {
+	Custom string `json:"custom"`
+	Value  int    `json:"value"`
+}
+
+func TestDecodeEvent_Registered(t *testing.T) {
+	EventDecoderMap["janus.plugin.custom"] = NewEventDecoder("custom", map[string]func() interface{}{
+		"tick": func() interface{} { return &customEvent{} },
+	})
+	defer delete(EventDecoderMap, "janus.plugin.custom")
+
+	event, err := DecodeEvent(pluginEvent("janus.plugin.custom", map[string]interface{}{"custom": "tick", "value": 3.0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tick, ok := event.(*customEvent); !ok || tick.Value != 3 {
+		t.Errorf("unexpected custom event %+v", event)
+	}
+
+	event, err = DecodeEvent(pluginEvent("janus.plugin.custom", map[string]interface{}{"custom": "tock"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := event.(map[string]interface{}); !ok {
+		t.Errorf("expected the raw plugin data, got %+v", event)
+	}
+
+	_, err = DecodeEvent(pluginEvent("janus.plugin.custom", map[string]interface{}{"custom": "tick", "value": "three"}))
+	if err == nil {
+		t.Error("expected an error decoding a malformed event")
+	}
+}
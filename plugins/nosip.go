@@ -0,0 +1,225 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+type NosipResponse struct {
+	Nosip string `json:"nosip"`
+}
+
+type NosipErrorResponse struct {
+	NosipResponse
+	PluginError
+}
+
+func (err *NosipErrorResponse) Error() string {
+	return err.PluginError.Error()
+}
+
+// NosipClient bridges a PeerConnection to plain RTP, on top of a handle
+// attached to janus.plugin.nosip. The signalling is left to the
+// application: the plugin only translates SDPs between WebRTC and plain RTP.
+type NosipClient struct {
+	Handle *janus.Handle
+}
+
+func NewNosipClient(handle *janus.Handle) *NosipClient {
+	return &NosipClient{Handle: handle}
+}
+
+// NosipGenerate holds the settings of a generate request. Info is opaque
+// data returned as is in the generated event.
+type NosipGenerate struct {
+	Info        string `json:"info,omitempty"`
+	Srtp        string `json:"srtp,omitempty"`
+	SrtpProfile string `json:"srtp_profile,omitempty"`
+	Update      bool   `json:"update,omitempty"`
+}
+
+// NosipProcess holds a plain RTP SDP to translate. Type is "offer" or
+// "answer".
+type NosipProcess struct {
+	Type   string `json:"type"`
+	Sdp    string `json:"sdp"`
+	Info   string `json:"info,omitempty"`
+	Srtp   string `json:"srtp,omitempty"`
+	Update bool   `json:"update,omitempty"`
+}
+
+// NosipRecording selects the media to start or stop recording: the audio
+// and video of the user, and the ones of the peer.
+type NosipRecording struct {
+	Audio     bool   `json:"audio,omitempty"`
+	Video     bool   `json:"video,omitempty"`
+	PeerAudio bool   `json:"peer_audio,omitempty"`
+	PeerVideo bool   `json:"peer_video,omitempty"`
+	Filename  string `json:"filename,omitempty"`
+}
+
+// NosipEvent is an event of the nosip plugin without a dedicated type, e.g.
+// the acknowledgement of a request ("hangingup", "recordingupdated").
+type NosipEvent struct {
+	Event  string                 `json:"event"`
+	Result map[string]interface{} `json:"-"`
+}
+
+// NosipGeneratedEvent carries the plain RTP SDP generated from the WebRTC
+// SDP of the user, to send to the peer through the signalling of the
+// application.
+type NosipGeneratedEvent struct {
+	Type   string `json:"type"`
+	Sdp    string `json:"sdp"`
+	Info   string `json:"info"`
+	Update bool   `json:"update"`
+}
+
+// NosipProcessedEvent answers a process request, with the WebRTC SDP for the
+// user in Jsep.
+type NosipProcessedEvent struct {
+	Srtp string                 `json:"srtp"`
+	Info string                 `json:"info"`
+	Jsep map[string]interface{} `json:"-"`
+}
+
+type NosipHangupEvent struct {
+	Reason string `json:"reason"`
+}
+
+// ParseNosipEvent decodes the plugin data of an event received from the
+// nosip plugin to one of the Nosip*Event types, to a *NosipEvent for the
+// events without a dedicated type, or to a *NosipErrorResponse. Other plugin
+// data is returned as is.
+func ParseNosipEvent(event *janus.EventMsg) (interface{}, error) {
+	data := event.Plugindata.Data
+	if _, ok := data["error"]; ok {
+		var errResp NosipErrorResponse
+		if err := decodePluginData(data, &errResp); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal nosip event : %w", err)
+		}
+		return &errResp, nil
+	}
+
+	result, ok := data["result"].(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+
+	var v interface{}
+	switch result["event"] {
+	case "generated":
+		v = &NosipGeneratedEvent{}
+	case "processed":
+		v = &NosipProcessedEvent{Jsep: event.Jsep}
+	case "hangup":
+		v = &NosipHangupEvent{}
+	default:
+		v = &NosipEvent{Result: result}
+	}
+
+	if err := decodePluginData(result, v); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal nosip event : %w", err)
+	}
+	return v, nil
+}
+
+// nosipMessage sends a request to the nosip plugin through handle, and
+// parses the event answering it.
+func nosipMessage(ctx context.Context, handle *janus.Handle, request string, body interface{}, jsep map[string]interface{}) (interface{}, error) {
+	return pluginMessage(ctx, handle, request, body, jsep, &NosipErrorResponse{}, ParseNosipEvent)
+}
+
+// expect sends a request the plugin acknowledges with the event ack.
+func (c *NosipClient) expect(ctx context.Context, request string, body interface{}, ack string) error {
+	event, err := nosipMessage(ctx, c.Handle, request, body, nil)
+	if err != nil {
+		return err
+	}
+	if e, ok := event.(*NosipEvent); !ok || e.Event != ack {
+		return fmt.Errorf("unexpected response to nosip %s: %v", request, event)
+	}
+	return nil
+}
+
+// Generate translates the WebRTC SDP offer or answer of the user to a plain
+// RTP one.
+// On success, the generated event will be returned and error will be nil.
+func (c *NosipClient) Generate(generate *NosipGenerate, jsep map[string]interface{}) (*NosipGeneratedEvent, error) {
+	return c.GenerateContext(context.Background(), generate, jsep)
+}
+
+// GenerateContext is like Generate, but gives up waiting for the response
+// when ctx is done.
+func (c *NosipClient) GenerateContext(ctx context.Context, generate *NosipGenerate, jsep map[string]interface{}) (*NosipGeneratedEvent, error) {
+	event, err := nosipMessage(ctx, c.Handle, "generate", generate, jsep)
+	if err != nil {
+		return nil, err
+	}
+	generated, ok := event.(*NosipGeneratedEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to nosip generate: %v", event)
+	}
+	return generated, nil
+}
+
+// Process translates the plain RTP SDP offer or answer of the peer to a
+// WebRTC one.
+// On success, the processed event carrying the SDP for the user will be
+// returned and error will be nil.
+func (c *NosipClient) Process(process *NosipProcess) (*NosipProcessedEvent, error) {
+	return c.ProcessContext(context.Background(), process)
+}
+
+// ProcessContext is like Process, but gives up waiting for the response when
+// ctx is done.
+func (c *NosipClient) ProcessContext(ctx context.Context, process *NosipProcess) (*NosipProcessedEvent, error) {
+	event, err := nosipMessage(ctx, c.Handle, "process", process, nil)
+	if err != nil {
+		return nil, err
+	}
+	processed, ok := event.(*NosipProcessedEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to nosip process: %v", event)
+	}
+	return processed, nil
+}
+
+// Hangup tears down the PeerConnection and the RTP session.
+// On success, error will be nil.
+func (c *NosipClient) Hangup() error {
+	return c.HangupContext(context.Background())
+}
+
+// HangupContext is like Hangup, but gives up waiting for the response when
+// ctx is done.
+func (c *NosipClient) HangupContext(ctx context.Context) error {
+	return c.expect(ctx, "hangup", nil, "hangingup")
+}
+
+// Recording starts or stops recording the media of the session.
+// On success, error will be nil.
+func (c *NosipClient) Recording(start bool, recording *NosipRecording) error {
+	return c.RecordingContext(context.Background(), start, recording)
+}
+
+// RecordingContext is like Recording, but gives up waiting for the response
+// when ctx is done.
+func (c *NosipClient) RecordingContext(ctx context.Context, start bool, recording *NosipRecording) error {
+	if recording == nil {
+		recording = &NosipRecording{}
+	}
+
+	body, err := janus.StructToMap(recording)
+	if err != nil {
+		return err
+	}
+	if start {
+		body["action"] = "start"
+	} else {
+		body["action"] = "stop"
+	}
+	return c.expect(ctx, "recording", body, "recordingupdated")
+}
@@ -0,0 +1,76 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestNosipClient(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	result := func(fields map[string]interface{}, jsep map[string]interface{}) *janustest.PluginResponse {
+		return event(map[string]interface{}{"nosip": "event", "result": fields}, jsep)
+	}
+	server.HandlePlugin("janus.plugin.nosip", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		switch msg.Body["request"] {
+		case "generate":
+			if msg.Jsep["type"] != "offer" || msg.Body["info"] != "call-1" {
+				t.Errorf("unexpected generate %v %v", msg.Body, msg.Jsep)
+			}
+			return result(map[string]interface{}{"event": "generated", "type": "offer", "sdp": "v=0 rtp", "info": "call-1"}, nil)
+		case "process":
+			if msg.Body["type"] != "answer" || msg.Body["sdp"] != "v=0 rtp" {
+				t.Errorf("unexpected process %v", msg.Body)
+			}
+			return result(map[string]interface{}{"event": "processed"}, map[string]interface{}{"type": "answer", "sdp": "v=0"})
+		case "recording":
+			if msg.Body["action"] != "stop" || msg.Body["peer_audio"] != true {
+				t.Errorf("unexpected recording %v", msg.Body)
+			}
+			return result(map[string]interface{}{"event": "recordingupdated"}, nil)
+		case "hangup":
+			return result(map[string]interface{}{"event": "hangingup"}, nil)
+		}
+		return nil
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.nosip")
+	defer gateway.Close()
+
+	client := NewNosipClient(handle)
+	generated, err := client.Generate(&NosipGenerate{Info: "call-1"}, map[string]interface{}{"type": "offer", "sdp": "v=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generated.Type != "offer" || generated.Sdp != "v=0 rtp" || generated.Info != "call-1" {
+		t.Errorf("unexpected generated event %+v", generated)
+	}
+
+	processed, err := client.Process(&NosipProcess{Type: "answer", Sdp: "v=0 rtp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if processed.Jsep["type"] != "answer" {
+		t.Errorf("unexpected processed event %+v", processed)
+	}
+
+	if err := client.Recording(false, &NosipRecording{PeerAudio: true}); err != nil {
+		t.Error(err)
+	}
+	if err := client.Hangup(); err != nil {
+		t.Error(err)
+	}
+
+	if err := server.PushEvent(server.Sessions()[0], handle.ID, map[string]interface{}{"nosip": "event", "result": map[string]interface{}{"event": "hangup", "reason": "Remote WebRTC hangup"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	event, err := ParseNosipEvent(nextEvent(t, handle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hangup, ok := event.(*NosipHangupEvent); !ok || hangup.Reason != "Remote WebRTC hangup" {
+		t.Errorf("unexpected hangup event %+v", event)
+	}
+}
@@ -0,0 +1,234 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+type RecordplayResponse struct {
+	Recordplay string `json:"recordplay"`
+}
+
+type RecordplayErrorResponse struct {
+	RecordplayResponse
+	PluginError
+}
+
+func (err *RecordplayErrorResponse) Error() string {
+	return err.PluginError.Error()
+}
+
+// RecordplayClient records a PeerConnection, or plays a recording back, on
+// top of a handle attached to janus.plugin.recordplay.
+type RecordplayClient struct {
+	Handle *janus.Handle
+
+	// RecordingID is the recording being recorded or played, if any.
+	RecordingID uint64
+}
+
+func NewRecordplayClient(handle *janus.Handle) *RecordplayClient {
+	return &RecordplayClient{Handle: handle}
+}
+
+// RecordplayRecord holds the settings of a record request. An ID of 0 lets
+// the plugin pick a random one, and an empty Filename derives the names of
+// the files from the ID.
+type RecordplayRecord struct {
+	Name         string `json:"name"`
+	ID           uint64 `json:"id,omitempty"`
+	Filename     string `json:"filename,omitempty"`
+	AudioCodec   string `json:"audiocodec,omitempty"`
+	VideoCodec   string `json:"videocodec,omitempty"`
+	VideoProfile string `json:"videoprofile,omitempty"`
+	OpusRed      bool   `json:"opusred,omitempty"`
+	TextData     bool   `json:"textdata,omitempty"`
+}
+
+// RecordplayRecording holds the metadata of a recording, and whether it has
+// audio, video and data.
+type RecordplayRecording struct {
+	ID         uint64 `json:"id"`
+	Name       string `json:"name"`
+	Date       string `json:"date"`
+	Audio      bool   `json:"audio"`
+	Video      bool   `json:"video"`
+	Data       bool   `json:"data"`
+	AudioCodec string `json:"audio_codec"`
+	VideoCodec string `json:"video_codec"`
+	DataCodec  string `json:"data_codec"`
+}
+
+type RecordplayListResponse struct {
+	RecordplayResponse
+	Recordings []*RecordplayRecording `json:"list"`
+}
+
+// RecordplayStatusEvent notifies a change of the status of the recording or
+// playout: "recording" (with the SDP answer of the plugin in Jsep),
+// "preparing" (with the SDP offer of the plugin in Jsep), "playing",
+// "stopped" or "done" when the playout reached the end of the recording.
+type RecordplayStatusEvent struct {
+	Status  string                 `json:"status"`
+	ID      uint64                 `json:"id"`
+	Warning string                 `json:"warning"`
+	Jsep    map[string]interface{} `json:"-"`
+}
+
+// ParseRecordplayEvent decodes the plugin data of an event received from the
+// recordplay plugin to a *RecordplayStatusEvent, or to a
+// *RecordplayErrorResponse. Unknown events are returned as the raw plugin
+// data.
+func ParseRecordplayEvent(event *janus.EventMsg) (interface{}, error) {
+	data := event.Plugindata.Data
+	if _, ok := data["error"]; ok {
+		var errResp RecordplayErrorResponse
+		if err := decodePluginData(data, &errResp); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal recordplay event : %w", err)
+		}
+		return &errResp, nil
+	}
+
+	result, ok := data["result"].(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+	if _, ok := result["status"]; !ok {
+		return data, nil
+	}
+
+	status := &RecordplayStatusEvent{Jsep: event.Jsep}
+	if err := decodePluginData(result, status); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal recordplay event : %w", err)
+	}
+	return status, nil
+}
+
+// recordplayMessage sends a request to the recordplay plugin through handle,
+// and parses the event answering it.
+func recordplayMessage(ctx context.Context, handle *janus.Handle, request string, body interface{}, jsep map[string]interface{}) (interface{}, error) {
+	return pluginMessage(ctx, handle, request, body, jsep, &RecordplayErrorResponse{}, ParseRecordplayEvent)
+}
+
+// status sends a request answered with a status event.
+func (c *RecordplayClient) status(ctx context.Context, request string, body interface{}, jsep map[string]interface{}) (*RecordplayStatusEvent, error) {
+	event, err := recordplayMessage(ctx, c.Handle, request, body, jsep)
+	if err != nil {
+		return nil, err
+	}
+	status, ok := event.(*RecordplayStatusEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to recordplay %s: %v", request, event)
+	}
+	return status, nil
+}
+
+// List lists the recordings available for playout.
+// On success, the metadata of the recordings will be returned and error will
+// be nil.
+func (c *RecordplayClient) List() ([]*RecordplayRecording, error) {
+	return c.ListContext(context.Background())
+}
+
+// ListContext is like List, but gives up waiting for the response when ctx
+// is done.
+func (c *RecordplayClient) ListContext(ctx context.Context) ([]*RecordplayRecording, error) {
+	data, err := handleRequest(ctx, c.Handle, map[string]interface{}{"request": "list"}, &RecordplayErrorResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp RecordplayListResponse
+	if err := decodePluginData(data, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Recordings, nil
+}
+
+// Update makes the plugin rescan its folder of recordings, e.g. after
+// recordings were added by an external tool.
+// On success, error will be nil.
+func (c *RecordplayClient) Update() error {
+	return c.UpdateContext(context.Background())
+}
+
+// UpdateContext is like Update, but gives up waiting for the response when
+// ctx is done.
+func (c *RecordplayClient) UpdateContext(ctx context.Context) error {
+	_, err := handleRequest(ctx, c.Handle, map[string]interface{}{"request": "update"}, &RecordplayErrorResponse{})
+	return err
+}
+
+// Record starts recording the PeerConnection negotiated with the SDP offer
+// of the user.
+// On success, the recording status event carrying the ID of the recording
+// and the SDP answer of the plugin will be returned and error will be nil.
+func (c *RecordplayClient) Record(record *RecordplayRecord, offer map[string]interface{}) (*RecordplayStatusEvent, error) {
+	return c.RecordContext(context.Background(), record, offer)
+}
+
+// RecordContext is like Record, but gives up waiting for the response when
+// ctx is done.
+func (c *RecordplayClient) RecordContext(ctx context.Context, record *RecordplayRecord, offer map[string]interface{}) (*RecordplayStatusEvent, error) {
+	status, err := c.status(ctx, "record", record, offer)
+	if err != nil {
+		return nil, err
+	}
+
+	c.RecordingID = status.ID
+	return status, nil
+}
+
+// Play asks to play the recording id back.
+// On success, the preparing status event carrying the SDP offer of the
+// plugin will be returned and error will be nil. The answer is sent with
+// Start.
+func (c *RecordplayClient) Play(id uint64) (*RecordplayStatusEvent, error) {
+	return c.PlayContext(context.Background(), id)
+}
+
+// PlayContext is like Play, but gives up waiting for the response when ctx
+// is done.
+func (c *RecordplayClient) PlayContext(ctx context.Context, id uint64) (*RecordplayStatusEvent, error) {
+	status, err := c.status(ctx, "play", map[string]interface{}{"id": id}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.RecordingID = id
+	return status, nil
+}
+
+// Start starts the playout with the SDP answer of the user.
+// On success, the playing status event will be returned and error will be
+// nil.
+func (c *RecordplayClient) Start(answer map[string]interface{}) (*RecordplayStatusEvent, error) {
+	return c.StartContext(context.Background(), answer)
+}
+
+// StartContext is like Start, but gives up waiting for the response when ctx
+// is done.
+func (c *RecordplayClient) StartContext(ctx context.Context, answer map[string]interface{}) (*RecordplayStatusEvent, error) {
+	return c.status(ctx, "start", nil, answer)
+}
+
+// Stop stops the recording or the playout.
+// On success, the stopped status event will be returned and error will be
+// nil.
+func (c *RecordplayClient) Stop() (*RecordplayStatusEvent, error) {
+	return c.StopContext(context.Background())
+}
+
+// StopContext is like Stop, but gives up waiting for the response when ctx
+// is done.
+func (c *RecordplayClient) StopContext(ctx context.Context) (*RecordplayStatusEvent, error) {
+	status, err := c.status(ctx, "stop", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.RecordingID = 0
+	return status, nil
+}
@@ -0,0 +1,88 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestRecordplayClient(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	status := func(s string, id interface{}, jsep map[string]interface{}) *janustest.PluginResponse {
+		return event(map[string]interface{}{"recordplay": "event", "result": map[string]interface{}{"status": s, "id": id}}, jsep)
+	}
+	server.HandlePlugin("janus.plugin.recordplay", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		switch msg.Body["request"] {
+		case "list":
+			return &janustest.PluginResponse{Data: map[string]interface{}{
+				"recordplay": "list",
+				"list": []interface{}{
+					map[string]interface{}{"id": 1234, "name": "call with alice", "date": "2021-03-04 05:06:07", "audio": true, "video": false, "data": false, "audio_codec": "opus"},
+				},
+			}}
+		case "update":
+			return &janustest.PluginResponse{Data: map[string]interface{}{"recordplay": "ok"}}
+		case "record":
+			if msg.Body["name"] != "call with bob" || msg.Jsep["type"] != "offer" {
+				t.Errorf("unexpected record %v %v", msg.Body, msg.Jsep)
+			}
+			return status("recording", 5678, map[string]interface{}{"type": "answer", "sdp": "v=0"})
+		case "play":
+			if msg.Body["id"] != 1234.0 {
+				return event(map[string]interface{}{"recordplay": "event", "error_code": 414, "error": "No such recording"}, nil)
+			}
+			return status("preparing", 1234, map[string]interface{}{"type": "offer", "sdp": "v=0"})
+		case "start":
+			return status("playing", nil, nil)
+		case "stop":
+			return status("stopped", 1234, nil)
+		}
+		return nil
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.recordplay")
+	defer gateway.Close()
+
+	client := NewRecordplayClient(handle)
+	recordings, err := client.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recordings) != 1 || recordings[0].ID != 1234 || !recordings[0].Audio || recordings[0].AudioCodec != "opus" {
+		t.Errorf("unexpected recordings %+v", recordings)
+	}
+	if err := client.Update(); err != nil {
+		t.Error(err)
+	}
+
+	recording, err := client.Record(&RecordplayRecord{Name: "call with bob"}, map[string]interface{}{"type": "offer", "sdp": "v=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recording.Status != "recording" || recording.ID != 5678 || client.RecordingID != 5678 || recording.Jsep["type"] != "answer" {
+		t.Errorf("unexpected record response %+v", recording)
+	}
+
+	_, err = client.Play(1)
+	if perr, ok := err.(*RecordplayErrorResponse); !ok || perr.Code != 414 {
+		t.Errorf("expected a RecordplayErrorResponse, got %v", err)
+	}
+	preparing, err := client.Play(1234)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preparing.Status != "preparing" || preparing.Jsep["type"] != "offer" || client.RecordingID != 1234 {
+		t.Errorf("unexpected play response %+v", preparing)
+	}
+	if playing, err := client.Start(map[string]interface{}{"type": "answer", "sdp": "v=0"}); err != nil || playing.Status != "playing" {
+		t.Errorf("unexpected start response %+v, %v", playing, err)
+	}
+	if stopped, err := client.Stop(); err != nil || stopped.Status != "stopped" {
+		t.Errorf("unexpected stop response %+v, %v", stopped, err)
+	}
+	if client.RecordingID != 0 {
+		t.Errorf("unexpected client state after stop %+v", client)
+	}
+}
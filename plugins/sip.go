@@ -0,0 +1,477 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+type SipResponse struct {
+	Sip string `json:"sip"`
+}
+
+type SipErrorResponse struct {
+	SipResponse
+	PluginError
+}
+
+func (err *SipErrorResponse) Error() string {
+	return err.PluginError.Error()
+}
+
+// SipClient is a SIP user agent, on top of a handle attached to
+// janus.plugin.sip. A handle takes part in a single call at a time, helper
+// sessions registered with RegisterHelper allow more calls for the same
+// account.
+type SipClient struct {
+	Handle *janus.Handle
+}
+
+func NewSipClient(handle *janus.Handle) *SipClient {
+	return &SipClient{Handle: handle}
+}
+
+// SipRegister holds the settings of a register request. Type is empty to
+// register with Secret or Ha1Secret (and Authuser if it differs from the
+// user of Username), "guest" to use the account without registering, or
+// "helper" to register a helper of the session MasterID.
+type SipRegister struct {
+	Type                   string            `json:"type,omitempty"`
+	SendRegister           *bool             `json:"send_register,omitempty"`
+	ForceUDP               bool              `json:"force_udp,omitempty"`
+	ForceTCP               bool              `json:"force_tcp,omitempty"`
+	Sips                   bool              `json:"sips,omitempty"`
+	Rfc2543Cancel          bool              `json:"rfc2543_cancel,omitempty"`
+	Username               string            `json:"username"`
+	Secret                 string            `json:"secret,omitempty"`
+	Ha1Secret              string            `json:"ha1_secret,omitempty"`
+	Authuser               string            `json:"authuser,omitempty"`
+	DisplayName            string            `json:"display_name,omitempty"`
+	UserAgent              string            `json:"user_agent,omitempty"`
+	Proxy                  string            `json:"proxy,omitempty"`
+	OutboundProxy          string            `json:"outbound_proxy,omitempty"`
+	Headers                map[string]string `json:"headers,omitempty"`
+	ContactParams          map[string]string `json:"contact_params,omitempty"`
+	IncomingHeaderPrefixes []string          `json:"incoming_header_prefixes,omitempty"`
+	Refresh                bool              `json:"refresh,omitempty"`
+	MasterID               uint64            `json:"master_id,omitempty"`
+	RegisterTTL            int               `json:"register_ttl,omitempty"`
+}
+
+// SipCall holds the settings of a call request. ReferID is the refer_id of
+// a SipTransferEvent, when the call follows a transfer.
+type SipCall struct {
+	URI                 string            `json:"uri"`
+	CallID              string            `json:"call_id,omitempty"`
+	ReferID             uint64            `json:"refer_id,omitempty"`
+	Headers             map[string]string `json:"headers,omitempty"`
+	Srtp                string            `json:"srtp,omitempty"`
+	SrtpProfile         string            `json:"srtp_profile,omitempty"`
+	AutoAcceptReinvites *bool             `json:"autoaccept_reinvites,omitempty"`
+	Secret              string            `json:"secret,omitempty"`
+	Ha1Secret           string            `json:"ha1_secret,omitempty"`
+	Authuser            string            `json:"authuser,omitempty"`
+}
+
+type SipAccept struct {
+	Srtp                string            `json:"srtp,omitempty"`
+	Headers             map[string]string `json:"headers,omitempty"`
+	AutoAcceptReinvites *bool             `json:"autoaccept_reinvites,omitempty"`
+}
+
+// SipRecording selects the media to start or stop recording: the audio and
+// video of the user, and the ones of the peer.
+type SipRecording struct {
+	Audio       bool   `json:"audio,omitempty"`
+	Video       bool   `json:"video,omitempty"`
+	PeerAudio   bool   `json:"peer_audio,omitempty"`
+	PeerVideo   bool   `json:"peer_video,omitempty"`
+	SendPli     bool   `json:"send_pli,omitempty"`
+	SendPeerPli bool   `json:"send_peer_pli,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+}
+
+// SipEvent is an event of the sip plugin without a dedicated type, e.g. the
+// acknowledgement of a request ("calling", "hangingup"...) or the
+// provisional responses of a call ("ringing", "proceeding").
+type SipEvent struct {
+	Event  string                 `json:"event"`
+	CallID string                 `json:"call_id"`
+	Result map[string]interface{} `json:"-"`
+}
+
+// SipRegisteredEvent notifies that the account Username is registered.
+// MasterID is the ID to register helpers of this session with.
+type SipRegisteredEvent struct {
+	Username     string `json:"username"`
+	RegisterSent bool   `json:"register_sent"`
+	MasterID     uint64 `json:"master_id"`
+}
+
+// SipRegistration is the response to a register request. Registered is set
+// when the account is usable right away, otherwise Pending is true and the
+// outcome arrives later on Handle.Events.
+type SipRegistration struct {
+	Registered *SipRegisteredEvent
+	Pending    bool
+}
+
+type SipRegistrationFailedEvent struct {
+	Code   int    `json:"code"`
+	Reason string `json:"reason"`
+}
+
+// SipIncomingCallEvent notifies an incoming call, with the SDP offer of the
+// caller in Jsep. It is accepted with Accept or refused with Decline.
+type SipIncomingCallEvent struct {
+	CallID      string                 `json:"call_id"`
+	Username    string                 `json:"username"`
+	DisplayName string                 `json:"displayname"`
+	Callee      string                 `json:"callee"`
+	ReferredBy  string                 `json:"referred_by"`
+	Srtp        string                 `json:"srtp"`
+	Headers     map[string]interface{} `json:"headers"`
+	Jsep        map[string]interface{} `json:"-"`
+}
+
+// SipAcceptedEvent notifies that the peer accepted a call, with its SDP
+// answer in Jsep.
+type SipAcceptedEvent struct {
+	CallID   string                 `json:"call_id"`
+	Username string                 `json:"username"`
+	Headers  map[string]interface{} `json:"headers"`
+	Jsep     map[string]interface{} `json:"-"`
+}
+
+// SipProgressEvent notifies early media (183 Session Progress), with the
+// SDP answer of the peer in Jsep.
+type SipProgressEvent struct {
+	CallID   string                 `json:"call_id"`
+	Username string                 `json:"username"`
+	Headers  map[string]interface{} `json:"headers"`
+	Jsep     map[string]interface{} `json:"-"`
+}
+
+type SipHangupEvent struct {
+	CallID       string `json:"call_id"`
+	Code         int    `json:"code"`
+	Reason       string `json:"reason"`
+	ReasonHeader string `json:"reason_header"`
+}
+
+// SipTransferEvent notifies that the peer asked to transfer the call to
+// ReferTo. The transfer is followed by calling ReferTo with ReferID.
+type SipTransferEvent struct {
+	CallID     string                 `json:"call_id"`
+	ReferID    uint64                 `json:"refer_id"`
+	ReferTo    string                 `json:"refer_to"`
+	ReferredBy string                 `json:"referred_by"`
+	Replaces   string                 `json:"replaces"`
+	Headers    map[string]interface{} `json:"headers"`
+}
+
+// ParseSipEvent decodes the plugin data of an event received from the sip
+// plugin to one of the Sip*Event types, to a *SipEvent for the events
+// without a dedicated type, or to a *SipErrorResponse. Other plugin data is
+// returned as is.
+func ParseSipEvent(event *janus.EventMsg) (interface{}, error) {
+	data := event.Plugindata.Data
+	if _, ok := data["error"]; ok {
+		var errResp SipErrorResponse
+		if err := decodePluginData(data, &errResp); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal sip event : %w", err)
+		}
+		return &errResp, nil
+	}
+
+	result, ok := data["result"].(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+
+	// The call ID is next to the result, except for incoming calls.
+	fields := make(map[string]interface{}, len(result)+1)
+	for k, v := range result {
+		fields[k] = v
+	}
+	if callID, ok := data["call_id"]; ok {
+		fields["call_id"] = callID
+	}
+
+	var v interface{}
+	switch result["event"] {
+	case "registered":
+		v = &SipRegisteredEvent{}
+	case "registration_failed":
+		v = &SipRegistrationFailedEvent{}
+	case "incomingcall":
+		v = &SipIncomingCallEvent{Jsep: event.Jsep}
+	case "accepted":
+		v = &SipAcceptedEvent{Jsep: event.Jsep}
+	case "progress":
+		v = &SipProgressEvent{Jsep: event.Jsep}
+	case "hangup":
+		v = &SipHangupEvent{}
+	case "transfer":
+		v = &SipTransferEvent{}
+	default:
+		v = &SipEvent{Result: result}
+	}
+
+	if err := decodePluginData(fields, v); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal sip event : %w", err)
+	}
+	return v, nil
+}
+
+// sipMessage sends a request to the sip plugin through handle, and parses
+// the event answering it.
+func sipMessage(ctx context.Context, handle *janus.Handle, request string, body interface{}, jsep map[string]interface{}) (interface{}, error) {
+	return pluginMessage(ctx, handle, request, body, jsep, &SipErrorResponse{}, ParseSipEvent)
+}
+
+// expect sends a request the plugin acknowledges with the event ack.
+func (c *SipClient) expect(ctx context.Context, request string, body interface{}, jsep map[string]interface{}, ack string) (*SipEvent, error) {
+	event, err := sipMessage(ctx, c.Handle, request, body, jsep)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := event.(*SipEvent)
+	if !ok || e.Event != ack {
+		return nil, fmt.Errorf("unexpected response to sip %s: %v", request, event)
+	}
+	return e, nil
+}
+
+// Register registers a SIP account.
+// On success, error will be nil. The registered event is returned when the
+// account is usable right away (guests, helpers, or SendRegister false).
+// Otherwise the registration is Pending, and its outcome arrives later on
+// Handle.Events as a *SipRegisteredEvent or a *SipRegistrationFailedEvent.
+func (c *SipClient) Register(register *SipRegister) (*SipRegistration, error) {
+	return c.RegisterContext(context.Background(), register)
+}
+
+// RegisterContext is like Register, but gives up waiting for the response
+// when ctx is done.
+func (c *SipClient) RegisterContext(ctx context.Context, register *SipRegister) (*SipRegistration, error) {
+	event, err := sipMessage(ctx, c.Handle, "register", register, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch e := event.(type) {
+	case *SipRegisteredEvent:
+		return &SipRegistration{Registered: e}, nil
+	case *SipEvent:
+		if e.Event == "registering" {
+			return &SipRegistration{Pending: true}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected response to sip register: %v", event)
+}
+
+// RegisterHelper makes the handle a helper of the session registered as
+// username with the master ID masterID, so it can take part in a call of
+// its own for the same account.
+// On success, error will be nil.
+func (c *SipClient) RegisterHelper(username string, masterID uint64) error {
+	return c.RegisterHelperContext(context.Background(), username, masterID)
+}
+
+// RegisterHelperContext is like RegisterHelper, but gives up waiting for the
+// response when ctx is done.
+func (c *SipClient) RegisterHelperContext(ctx context.Context, username string, masterID uint64) error {
+	_, err := c.RegisterContext(ctx, &SipRegister{Type: "helper", Username: username, MasterID: masterID})
+	return err
+}
+
+// Unregister unregisters the SIP account.
+// On success, error will be nil. The outcome is notified later by an
+// unregistered SipEvent.
+func (c *SipClient) Unregister() error {
+	return c.UnregisterContext(context.Background())
+}
+
+// UnregisterContext is like Unregister, but gives up waiting for the response
+// when ctx is done.
+func (c *SipClient) UnregisterContext(ctx context.Context) error {
+	_, err := c.expect(ctx, "unregister", nil, nil, "unregistering")
+	return err
+}
+
+// Call calls a SIP URI with the SDP offer of the user.
+// On success, the ID of the call will be returned and error will be nil. The
+// progress of the call is notified by events.
+func (c *SipClient) Call(call *SipCall, offer map[string]interface{}) (string, error) {
+	return c.CallContext(context.Background(), call, offer)
+}
+
+// CallContext is like Call, but gives up waiting for the response when ctx
+// is done.
+func (c *SipClient) CallContext(ctx context.Context, call *SipCall, offer map[string]interface{}) (string, error) {
+	calling, err := c.expect(ctx, "call", call, offer, "calling")
+	if err != nil {
+		return "", err
+	}
+	return calling.CallID, nil
+}
+
+// Accept accepts an incoming call with the SDP answer of the user.
+// On success, error will be nil.
+func (c *SipClient) Accept(accept *SipAccept, answer map[string]interface{}) error {
+	return c.AcceptContext(context.Background(), accept, answer)
+}
+
+// AcceptContext is like Accept, but gives up waiting for the response when
+// ctx is done.
+func (c *SipClient) AcceptContext(ctx context.Context, accept *SipAccept, answer map[string]interface{}) error {
+	_, err := c.expect(ctx, "accept", accept, answer, "accepting")
+	return err
+}
+
+// Decline refuses an incoming call with the SIP response code, 486 Busy
+// Here if 0.
+// On success, error will be nil.
+func (c *SipClient) Decline(code int, headers map[string]string) error {
+	return c.DeclineContext(context.Background(), code, headers)
+}
+
+// DeclineContext is like Decline, but gives up waiting for the response when
+// ctx is done.
+func (c *SipClient) DeclineContext(ctx context.Context, code int, headers map[string]string) error {
+	body := map[string]interface{}{}
+	if code != 0 {
+		body["code"] = code
+	}
+	if len(headers) > 0 {
+		body["headers"] = headers
+	}
+	_, err := c.expect(ctx, "decline", body, nil, "declining")
+	return err
+}
+
+// Hold puts the call on hold. direction is the SDP direction offered to the
+// peer, "sendonly" if empty.
+// On success, error will be nil.
+func (c *SipClient) Hold(direction string) error {
+	return c.HoldContext(context.Background(), direction)
+}
+
+// HoldContext is like Hold, but gives up waiting for the response when ctx is
+// done.
+func (c *SipClient) HoldContext(ctx context.Context, direction string) error {
+	body := map[string]interface{}{}
+	if direction != "" {
+		body["direction"] = direction
+	}
+	_, err := c.expect(ctx, "hold", body, nil, "holding")
+	return err
+}
+
+// Unhold resumes a call put on hold.
+// On success, error will be nil.
+func (c *SipClient) Unhold() error {
+	return c.UnholdContext(context.Background())
+}
+
+// UnholdContext is like Unhold, but gives up waiting for the response when
+// ctx is done.
+func (c *SipClient) UnholdContext(ctx context.Context) error {
+	_, err := c.expect(ctx, "unhold", nil, nil, "resuming")
+	return err
+}
+
+// Update renegotiates the call with a new SDP offer of the user, e.g. for an
+// ICE restart.
+// On success, error will be nil.
+func (c *SipClient) Update(offer map[string]interface{}) error {
+	return c.UpdateContext(context.Background(), offer)
+}
+
+// UpdateContext is like Update, but gives up waiting for the response when
+// ctx is done.
+func (c *SipClient) UpdateContext(ctx context.Context, offer map[string]interface{}) error {
+	_, err := c.expect(ctx, "update", nil, offer, "updating")
+	return err
+}
+
+// Hangup hangs up the call.
+// On success, error will be nil. The end of the call is notified later by a
+// SipHangupEvent.
+func (c *SipClient) Hangup(headers map[string]string) error {
+	return c.HangupContext(context.Background(), headers)
+}
+
+// HangupContext is like Hangup, but gives up waiting for the response when
+// ctx is done.
+func (c *SipClient) HangupContext(ctx context.Context, headers map[string]string) error {
+	body := map[string]interface{}{}
+	if len(headers) > 0 {
+		body["headers"] = headers
+	}
+	_, err := c.expect(ctx, "hangup", body, nil, "hangingup")
+	return err
+}
+
+// DtmfInfo sends a DTMF digit through a SIP INFO request. duration is in
+// milliseconds, 160 if 0.
+// On success, error will be nil.
+func (c *SipClient) DtmfInfo(digit string, duration int) error {
+	return c.DtmfInfoContext(context.Background(), digit, duration)
+}
+
+// DtmfInfoContext is like DtmfInfo, but gives up waiting for the response
+// when ctx is done.
+func (c *SipClient) DtmfInfoContext(ctx context.Context, digit string, duration int) error {
+	body := map[string]interface{}{"digit": digit}
+	if duration != 0 {
+		body["duration"] = duration
+	}
+	_, err := c.expect(ctx, "dtmf_info", body, nil, "dtmfsent")
+	return err
+}
+
+// Transfer transfers the call to uri (blind transfer), or replaces the call
+// replace with it (attended transfer).
+// On success, error will be nil.
+func (c *SipClient) Transfer(uri, replace string) error {
+	return c.TransferContext(context.Background(), uri, replace)
+}
+
+// TransferContext is like Transfer, but gives up waiting for the response
+// when ctx is done.
+func (c *SipClient) TransferContext(ctx context.Context, uri, replace string) error {
+	body := map[string]interface{}{"uri": uri}
+	if replace != "" {
+		body["replace"] = replace
+	}
+	_, err := c.expect(ctx, "transfer", body, nil, "transferring")
+	return err
+}
+
+// Recording starts or stops recording the media of the call.
+// On success, error will be nil.
+func (c *SipClient) Recording(start bool, recording *SipRecording) error {
+	return c.RecordingContext(context.Background(), start, recording)
+}
+
+// RecordingContext is like Recording, but gives up waiting for the response
+// when ctx is done.
+func (c *SipClient) RecordingContext(ctx context.Context, start bool, recording *SipRecording) error {
+	if recording == nil {
+		recording = &SipRecording{}
+	}
+
+	body, err := janus.StructToMap(recording)
+	if err != nil {
+		return err
+	}
+	if start {
+		body["action"] = "start"
+	} else {
+		body["action"] = "stop"
+	}
+	_, err = c.expect(ctx, "recording", body, nil, "recordingupdated")
+	return err
+}
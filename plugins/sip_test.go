@@ -0,0 +1,195 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestSipClient(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	result := func(name string, fields map[string]interface{}, jsep map[string]interface{}) *janustest.PluginResponse {
+		r := map[string]interface{}{"event": name}
+		for k, v := range fields {
+			r[k] = v
+		}
+		return event(map[string]interface{}{"sip": "event", "call_id": "c1", "result": r}, jsep)
+	}
+	server.HandlePlugin("janus.plugin.sip", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		switch msg.Body["request"] {
+		case "register":
+			switch msg.Body["type"] {
+			case "guest":
+				return result("registered", map[string]interface{}{"username": msg.Body["username"], "register_sent": false, "master_id": 12}, nil)
+			case "helper":
+				if msg.Body["master_id"] != 12.0 {
+					t.Errorf("unexpected helper register %v", msg.Body)
+				}
+				return result("registered", map[string]interface{}{"username": msg.Body["username"], "register_sent": false}, nil)
+			}
+			if msg.Body["ha1_secret"] != "0123456789abcdef" || msg.Body["authuser"] != "bob-auth" {
+				t.Errorf("unexpected register %v", msg.Body)
+			}
+			return result("registering", nil, nil)
+		case "unregister":
+			return result("unregistering", nil, nil)
+		case "call":
+			if msg.Body["uri"] != "sip:alice@example.com" || msg.Jsep["type"] != "offer" {
+				t.Errorf("unexpected call %v %v", msg.Body, msg.Jsep)
+			}
+			return result("calling", nil, nil)
+		case "accept":
+			return result("accepting", nil, nil)
+		case "decline":
+			if msg.Body["code"] != 603.0 {
+				t.Errorf("unexpected decline %v", msg.Body)
+			}
+			return result("declining", map[string]interface{}{"code": 603}, nil)
+		case "hold":
+			if msg.Body["direction"] != "inactive" {
+				t.Errorf("unexpected hold %v", msg.Body)
+			}
+			return result("holding", nil, nil)
+		case "unhold":
+			return result("resuming", nil, nil)
+		case "update":
+			return result("updating", nil, nil)
+		case "dtmf_info":
+			if msg.Body["digit"] != "5" || msg.Body["duration"] != nil {
+				t.Errorf("unexpected dtmf_info %v", msg.Body)
+			}
+			return result("dtmfsent", nil, nil)
+		case "transfer":
+			if msg.Body["uri"] != "sip:carol@example.com" {
+				t.Errorf("unexpected transfer %v", msg.Body)
+			}
+			return result("transferring", nil, nil)
+		case "recording":
+			if msg.Body["action"] != "start" || msg.Body["peer_audio"] != true {
+				t.Errorf("unexpected recording %v", msg.Body)
+			}
+			return result("recordingupdated", nil, nil)
+		case "hangup":
+			return result("hangingup", nil, nil)
+		}
+		return nil
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.sip")
+	defer gateway.Close()
+
+	client := NewSipClient(handle)
+	registration, err := client.Register(&SipRegister{Username: "sip:bob@example.com", Ha1Secret: "0123456789abcdef", Authuser: "bob-auth"})
+	if err != nil || !registration.Pending || registration.Registered != nil {
+		t.Errorf("expected a pending registration, got %+v, %v", registration, err)
+	}
+	registration, err = client.Register(&SipRegister{Type: "guest", Username: "sip:bob@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered := registration.Registered
+	if registration.Pending || registered == nil {
+		t.Fatalf("expected a SipRegisteredEvent, got %+v", registration)
+	}
+	if registered.Username != "sip:bob@example.com" || registered.MasterID != 12 {
+		t.Errorf("unexpected registered event %+v", registered)
+	}
+	if err := client.RegisterHelper("sip:bob@example.com", registered.MasterID); err != nil {
+		t.Error(err)
+	}
+
+	callID, err := client.Call(&SipCall{URI: "sip:alice@example.com"}, map[string]interface{}{"type": "offer", "sdp": "v=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if callID != "c1" {
+		t.Errorf("unexpected call ID %q", callID)
+	}
+
+	for name, request := range map[string]func() error{
+		"accept":     func() error { return client.Accept(nil, map[string]interface{}{"type": "answer", "sdp": "v=0"}) },
+		"decline":    func() error { return client.Decline(603, nil) },
+		"hold":       func() error { return client.Hold("inactive") },
+		"unhold":     client.Unhold,
+		"update":     func() error { return client.Update(map[string]interface{}{"type": "offer", "sdp": "v=0"}) },
+		"dtmf_info":  func() error { return client.DtmfInfo("5", 0) },
+		"transfer":   func() error { return client.Transfer("sip:carol@example.com", "") },
+		"recording":  func() error { return client.Recording(true, &SipRecording{Audio: true, PeerAudio: true}) },
+		"hangup":     func() error { return client.Hangup(nil) },
+		"unregister": client.Unregister,
+	} {
+		if err := request(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestParseSipEvent(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.sip")
+	defer gateway.Close()
+
+	push := func(data map[string]interface{}, jsep map[string]interface{}) interface{} {
+		if err := server.PushEvent(server.Sessions()[0], handle.ID, data, jsep); err != nil {
+			t.Fatal(err)
+		}
+		event, err := ParseSipEvent(nextEvent(t, handle))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+	offer := map[string]interface{}{"type": "offer", "sdp": "v=0"}
+
+	incoming, ok := push(map[string]interface{}{
+		"sip":     "event",
+		"call_id": "c2",
+		"result": map[string]interface{}{
+			"event":       "incomingcall",
+			"username":    "sip:alice@example.com",
+			"displayname": "Alice",
+			"callee":      "sip:bob@example.com",
+			"headers":     map[string]interface{}{"X-Foo": "bar"},
+		},
+	}, offer).(*SipIncomingCallEvent)
+	if !ok || incoming.CallID != "c2" || incoming.DisplayName != "Alice" || incoming.Jsep["type"] != "offer" || incoming.Headers["X-Foo"] != "bar" {
+		t.Errorf("unexpected incomingcall event %+v", incoming)
+	}
+
+	accepted, ok := push(map[string]interface{}{"sip": "event", "call_id": "c2", "result": map[string]interface{}{"event": "accepted", "username": "sip:alice@example.com"}}, offer).(*SipAcceptedEvent)
+	if !ok || accepted.CallID != "c2" || accepted.Jsep == nil {
+		t.Errorf("unexpected accepted event %+v", accepted)
+	}
+
+	if _, ok := push(map[string]interface{}{"sip": "event", "result": map[string]interface{}{"event": "progress"}}, offer).(*SipProgressEvent); !ok {
+		t.Error("expected a SipProgressEvent")
+	}
+
+	transfer, ok := push(map[string]interface{}{"sip": "event", "call_id": "c2", "result": map[string]interface{}{"event": "transfer", "refer_id": 3, "refer_to": "sip:carol@example.com"}}, nil).(*SipTransferEvent)
+	if !ok || transfer.ReferID != 3 || transfer.ReferTo != "sip:carol@example.com" {
+		t.Errorf("unexpected transfer event %+v", transfer)
+	}
+
+	hangup, ok := push(map[string]interface{}{"sip": "event", "call_id": "c2", "result": map[string]interface{}{"event": "hangup", "code": 200, "reason": "BYE"}}, nil).(*SipHangupEvent)
+	if !ok || hangup.Code != 200 || hangup.Reason != "BYE" {
+		t.Errorf("unexpected hangup event %+v", hangup)
+	}
+
+	failed, ok := push(map[string]interface{}{"sip": "event", "result": map[string]interface{}{"event": "registration_failed", "code": 403, "reason": "Forbidden"}}, nil).(*SipRegistrationFailedEvent)
+	if !ok || failed.Code != 403 {
+		t.Errorf("unexpected registration_failed event %+v", failed)
+	}
+
+	ringing, ok := push(map[string]interface{}{"sip": "event", "call_id": "c2", "result": map[string]interface{}{"event": "ringing"}}, nil).(*SipEvent)
+	if !ok || ringing.Event != "ringing" || ringing.CallID != "c2" {
+		t.Errorf("unexpected ringing event %+v", ringing)
+	}
+
+	if _, ok := push(map[string]interface{}{"sip": "event", "error_code": 449, "error": "Invalid address"}, nil).(*SipErrorResponse); !ok {
+		t.Error("expected a SipErrorResponse")
+	}
+}
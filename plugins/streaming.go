@@ -0,0 +1,443 @@
+package plugins
+
+import (
+	"github.com/tatsujin1/janus-go"
+)
+
+type StreamingResponse struct {
+	Streaming string `json:"streaming"`
+}
+
+type StreamingErrorResponse struct {
+	StreamingResponse
+	PluginError
+}
+
+func (err *StreamingErrorResponse) Error() string {
+	return err.PluginError.Error()
+}
+
+type StreamingRequestFactory struct {
+	PluginRequestFactory
+}
+
+func MakeStreamingRequestFactory(adminKey string) *StreamingRequestFactory {
+	return &StreamingRequestFactory{
+		PluginRequestFactory: *NewPluginRequestFactory("janus.plugin.streaming", adminKey),
+	}
+}
+
+func (f *StreamingRequestFactory) ListRequest() *BasePluginRequest {
+	request := f.make("list")
+	return &request
+}
+
+func (f *StreamingRequestFactory) InfoRequest(mountpointID int, secret string) *StreamingMountpointRequest {
+	return &StreamingMountpointRequest{
+		BasePluginRequest: f.make("info"),
+		MountpointID:      mountpointID,
+		Secret:            secret,
+	}
+}
+
+// CreateRtpRequest creates a mountpoint relaying RTP streams sent to the
+// plugin by an external tool (e.g. gstreamer or ffmpeg).
+func (f *StreamingRequestFactory) CreateRtpRequest(mountpoint *StreamingRtpMountpoint, permanent bool) *StreamingCreateRequest {
+	return f.createRequest("rtp", mountpoint, permanent)
+}
+
+// CreateLiveRequest creates a mountpoint streaming a local file live, all the
+// viewers sharing the same streaming context.
+func (f *StreamingRequestFactory) CreateLiveRequest(mountpoint *StreamingFileMountpoint, permanent bool) *StreamingCreateRequest {
+	return f.createRequest("live", mountpoint, permanent)
+}
+
+// CreateOndemandRequest creates a mountpoint streaming a local file from the
+// start for each viewer.
+func (f *StreamingRequestFactory) CreateOndemandRequest(mountpoint *StreamingFileMountpoint, permanent bool) *StreamingCreateRequest {
+	return f.createRequest("ondemand", mountpoint, permanent)
+}
+
+// CreateRtspRequest creates a mountpoint relaying an external RTSP feed.
+func (f *StreamingRequestFactory) CreateRtspRequest(mountpoint *StreamingRtspMountpoint, permanent bool) *StreamingCreateRequest {
+	return f.createRequest("rtsp", mountpoint, permanent)
+}
+
+func (f *StreamingRequestFactory) createRequest(mountpointType string, mountpoint StreamingMountpointSettings, permanent bool) *StreamingCreateRequest {
+	return &StreamingCreateRequest{
+		BasePluginRequest: f.make("create"),
+		Type:              mountpointType,
+		Mountpoint:        mountpoint,
+		Permanent:         permanent,
+	}
+}
+
+func (f *StreamingRequestFactory) EditRequest(mountpoint *StreamingMountpointForEdit, permanent bool, secret string) *StreamingEditRequest {
+	return &StreamingEditRequest{
+		BasePluginRequest: f.make("edit"),
+		Mountpoint:        mountpoint,
+		Permanent:         permanent,
+		Secret:            secret,
+	}
+}
+
+func (f *StreamingRequestFactory) DestroyRequest(mountpointID int, permanent bool, secret string) *StreamingDestroyRequest {
+	return &StreamingDestroyRequest{
+		BasePluginRequest: f.make("destroy"),
+		MountpointID:      mountpointID,
+		Permanent:         permanent,
+		Secret:            secret,
+	}
+}
+
+func (f *StreamingRequestFactory) EnableRequest(mountpointID int, secret string) *StreamingMountpointRequest {
+	return &StreamingMountpointRequest{
+		BasePluginRequest: f.make("enable"),
+		MountpointID:      mountpointID,
+		Secret:            secret,
+	}
+}
+
+// DisableRequest disables a mountpoint, which keeps existing but can't be
+// watched until enabled again.
+func (f *StreamingRequestFactory) DisableRequest(mountpointID int, secret string) *StreamingMountpointRequest {
+	return &StreamingMountpointRequest{
+		BasePluginRequest: f.make("disable"),
+		MountpointID:      mountpointID,
+		Secret:            secret,
+	}
+}
+
+// StartRecordingRequest records the streams of a mountpoint to the given
+// files. Streams with an empty filename are not recorded.
+func (f *StreamingRequestFactory) StartRecordingRequest(mountpointID int, secret string, audio, video, data string) *StreamingStartRecordingRequest {
+	return &StreamingStartRecordingRequest{
+		BasePluginRequest: f.make("recording"),
+		MountpointID:      mountpointID,
+		Secret:            secret,
+		Audio:             audio,
+		Video:             video,
+		Data:              data,
+	}
+}
+
+func (f *StreamingRequestFactory) StopRecordingRequest(mountpointID int, secret string, audio, video, data bool) *StreamingStopRecordingRequest {
+	return &StreamingStopRecordingRequest{
+		BasePluginRequest: f.make("recording"),
+		MountpointID:      mountpointID,
+		Secret:            secret,
+		Audio:             audio,
+		Video:             video,
+		Data:              data,
+	}
+}
+
+// StreamingMountpointSettings are the settings of a mountpoint to create,
+// one of the Streaming*Mountpoint types.
+type StreamingMountpointSettings interface {
+	AsMap() map[string]interface{}
+}
+
+type StreamingListResponse struct {
+	StreamingResponse
+	Mountpoints []*StreamingMountpointFromListResponse `json:"list"`
+}
+
+// StreamingMountpointRequest is a request about a mountpoint, which takes no
+// other parameter than the mountpoint secret.
+type StreamingMountpointRequest struct {
+	BasePluginRequest
+	MountpointID int
+	Secret       string
+}
+
+func (r *StreamingMountpointRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["id"] = r.MountpointID
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type StreamingInfoResponse struct {
+	StreamingResponse
+	Info *StreamingMountpointInfo `json:"info"`
+}
+
+type StreamingCreateRequest struct {
+	BasePluginRequest
+	Type       string
+	Mountpoint StreamingMountpointSettings
+	Permanent  bool
+}
+
+func (r *StreamingCreateRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["type"] = r.Type
+	payload["permanent"] = r.Permanent
+	mergeMap(payload, r.Mountpoint.AsMap())
+	return payload
+}
+
+type StreamingCreateResponse struct {
+	StreamingResponse
+	Name      string                  `json:"create"`
+	Permanent bool                    `json:"permanent"`
+	Stream    *StreamingCreatedStream `json:"stream"`
+}
+
+// StreamingCreatedStream describes a created mountpoint. The ports are the
+// ones the plugin bound for rtp mountpoints, e.g. when created with port 0.
+type StreamingCreatedStream struct {
+	ID            int    `json:"id"`
+	Type          string `json:"type"`
+	Description   string `json:"description"`
+	IsPrivate     bool   `json:"is_private"`
+	AudioPort     int    `json:"audio_port"`
+	AudioRtcpPort int    `json:"audio_rtcp_port"`
+	VideoPort     int    `json:"video_port"`
+	VideoRtcpPort int    `json:"video_rtcp_port"`
+	VideoPort2    int    `json:"video_port_2"`
+	VideoPort3    int    `json:"video_port_3"`
+	DataPort      int    `json:"data_port"`
+}
+
+type StreamingEditRequest struct {
+	BasePluginRequest
+	Mountpoint *StreamingMountpointForEdit
+	Secret     string
+	Permanent  bool
+}
+
+func (r *StreamingEditRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["permanent"] = r.Permanent
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	mergeMap(payload, r.Mountpoint.AsMap())
+	return payload
+}
+
+type StreamingEditResponse struct {
+	StreamingResponse
+	MountpointID int  `json:"id"`
+	Permanent    bool `json:"permanent"`
+}
+
+type StreamingDestroyRequest struct {
+	BasePluginRequest
+	MountpointID int
+	Secret       string
+	Permanent    bool
+}
+
+func (r *StreamingDestroyRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["id"] = r.MountpointID
+	payload["permanent"] = r.Permanent
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type StreamingDestroyResponse struct {
+	StreamingResponse
+	MountpointID int `json:"id"`
+}
+
+type StreamingStartRecordingRequest struct {
+	BasePluginRequest
+	MountpointID int
+	Secret       string
+	Audio        string
+	Video        string
+	Data         string
+}
+
+func (r *StreamingStartRecordingRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["action"] = "start"
+	payload["id"] = r.MountpointID
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	if r.Audio != "" {
+		payload["audio"] = r.Audio
+	}
+	if r.Video != "" {
+		payload["video"] = r.Video
+	}
+	if r.Data != "" {
+		payload["data"] = r.Data
+	}
+	return payload
+}
+
+type StreamingStopRecordingRequest struct {
+	BasePluginRequest
+	MountpointID int
+	Secret       string
+	Audio        bool
+	Video        bool
+	Data         bool
+}
+
+func (r *StreamingStopRecordingRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["action"] = "stop"
+	payload["id"] = r.MountpointID
+	payload["audio"] = r.Audio
+	payload["video"] = r.Video
+	payload["data"] = r.Data
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+// StreamingOkResponse answers the enable, disable and recording requests.
+type StreamingOkResponse struct {
+	StreamingResponse
+}
+
+// StreamingMountpoint holds the settings common to all the types of
+// mountpoints. An ID of 0 lets the plugin pick a random one.
+type StreamingMountpoint struct {
+	ID          int    `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Metadata    string `json:"metadata,omitempty"`
+	IsPrivate   bool   `json:"is_private"`
+	Secret      string `json:"secret,omitempty"`
+	Pin         string `json:"pin,omitempty"`
+	Audio       bool   `json:"audio"`
+	Video       bool   `json:"video"`
+}
+
+type StreamingRtpMountpoint struct {
+	StreamingMountpoint
+	Data           bool   `json:"data"`
+	AudioPort      int    `json:"audioport,omitempty"`
+	AudioRtcpPort  int    `json:"audiortcpport,omitempty"`
+	AudioMcast     string `json:"audiomcast,omitempty"`
+	AudioIface     string `json:"audioiface,omitempty"`
+	AudioPT        int    `json:"audiopt,omitempty"`
+	AudioRtpMap    string `json:"audiortpmap,omitempty"`
+	AudioFmtp      string `json:"audiofmtp,omitempty"`
+	AudioSkew      bool   `json:"audioskew,omitempty"`
+	VideoPort      int    `json:"videoport,omitempty"`
+	VideoRtcpPort  int    `json:"videortcpport,omitempty"`
+	VideoMcast     string `json:"videomcast,omitempty"`
+	VideoIface     string `json:"videoiface,omitempty"`
+	VideoPT        int    `json:"videopt,omitempty"`
+	VideoRtpMap    string `json:"videortpmap,omitempty"`
+	VideoFmtp      string `json:"videofmtp,omitempty"`
+	VideoBufferKF  bool   `json:"videobufferkf,omitempty"`
+	VideoSimulcast bool   `json:"videosimulcast,omitempty"`
+	VideoPort2     int    `json:"videoport2,omitempty"`
+	VideoPort3     int    `json:"videoport3,omitempty"`
+	VideoSkew      bool   `json:"videoskew,omitempty"`
+	VideoSvc       bool   `json:"videosvc,omitempty"`
+	Collision      int    `json:"collision,omitempty"`
+	DataPort       int    `json:"dataport,omitempty"`
+	DataIface      string `json:"dataiface,omitempty"`
+	DataType       string `json:"datatype,omitempty"`
+	DataBufferMsg  bool   `json:"databuffermsg,omitempty"`
+	Threads        int    `json:"threads,omitempty"`
+	SrtpSuite      int    `json:"srtpsuite,omitempty"`
+	SrtpCrypto     string `json:"srtpcrypto,omitempty"`
+}
+
+func (m *StreamingRtpMountpoint) AsMap() map[string]interface{} {
+	r, _ := janus.StructToMap(m)
+	return r
+}
+
+// StreamingFileMountpoint is a live or ondemand mountpoint, streaming a local
+// file.
+type StreamingFileMountpoint struct {
+	StreamingMountpoint
+	Filename string `json:"filename"`
+}
+
+func (m *StreamingFileMountpoint) AsMap() map[string]interface{} {
+	r, _ := janus.StructToMap(m)
+	return r
+}
+
+type StreamingRtspMountpoint struct {
+	StreamingMountpoint
+	URL           string `json:"url"`
+	RtspUser      string `json:"rtsp_user,omitempty"`
+	RtspPwd       string `json:"rtsp_pwd,omitempty"`
+	RtspIface     string `json:"rtspiface,omitempty"`
+	RtspFailcheck *bool  `json:"rtsp_failcheck,omitempty"`
+	AudioPT       int    `json:"audiopt,omitempty"`
+	AudioRtpMap   string `json:"audiortpmap,omitempty"`
+	AudioFmtp     string `json:"audiofmtp,omitempty"`
+	VideoPT       int    `json:"videopt,omitempty"`
+	VideoRtpMap   string `json:"videortpmap,omitempty"`
+	VideoFmtp     string `json:"videofmtp,omitempty"`
+}
+
+func (m *StreamingRtspMountpoint) AsMap() map[string]interface{} {
+	r, _ := janus.StructToMap(m)
+	return r
+}
+
+type StreamingMountpointFromListResponse struct {
+	ID          int    `json:"id"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Metadata    string `json:"metadata"`
+	Enabled     bool   `json:"enabled"`
+	AudioAgeMs  int    `json:"audio_age_ms"`
+	VideoAgeMs  int    `json:"video_age_ms"`
+}
+
+// StreamingMountpointInfo is the description of a mountpoint returned by an
+// info request. Secret and Pin are only returned when the request carried
+// the secret of the mountpoint.
+type StreamingMountpointInfo struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Metadata    string `json:"metadata"`
+	Secret      string `json:"secret"`
+	Pin         string `json:"pin"`
+	IsPrivate   bool   `json:"is_private"`
+	Enabled     bool   `json:"enabled"`
+	Viewers     int    `json:"viewers"`
+	Type        string `json:"type"`
+	Audio       bool   `json:"audio"`
+	Video       bool   `json:"video"`
+	Data        bool   `json:"data"`
+	AudioAgeMs  int    `json:"audio_age_ms"`
+	VideoAgeMs  int    `json:"video_age_ms"`
+	AudioPT     int    `json:"audiopt"`
+	AudioRtpMap string `json:"audiortpmap"`
+	AudioFmtp   string `json:"audiofmtp"`
+	VideoPT     int    `json:"videopt"`
+	VideoRtpMap string `json:"videortpmap"`
+	VideoFmtp   string `json:"videofmtp"`
+	AudioFile   string `json:"audio_file"`
+	VideoFile   string `json:"video_file"`
+	DataFile    string `json:"data_file"`
+}
+
+type StreamingMountpointForEdit struct {
+	ID          int    `json:"id"`
+	Description string `json:"new_description,omitempty"`
+	Metadata    string `json:"new_metadata,omitempty"`
+	IsPrivate   bool   `json:"new_is_private"`
+	Secret      string `json:"new_secret,omitempty"`
+	Pin         string `json:"new_pin,omitempty"`
+}
+
+func (m *StreamingMountpointForEdit) AsMap() map[string]interface{} {
+	r, _ := janus.StructToMap(m)
+	return r
+}
@@ -0,0 +1,41 @@
+package plugins
+
+import "testing"
+
+func TestStreamingCreateRequest_Payload(t *testing.T) {
+	f := MakeStreamingRequestFactory("supersecret")
+	mountpoint := &StreamingRtpMountpoint{
+		StreamingMountpoint: StreamingMountpoint{ID: 99, Audio: true},
+		AudioPort:           5002,
+		AudioPT:             111,
+		AudioRtpMap:         "opus/48000/2",
+	}
+	payload := f.CreateRtpRequest(mountpoint, false).Payload()
+	if payload["request"] != "create" || payload["type"] != "rtp" || payload["admin_key"] != "supersecret" {
+		t.Errorf("unexpected payload %v", payload)
+	}
+	if payload["id"] != 99.0 || payload["audioport"] != 5002.0 || payload["audio"] != true {
+		t.Errorf("mountpoint settings not merged in payload %v", payload)
+	}
+	for _, k := range []string{"name", "secret", "pin", "videoport", "videortpmap", "srtpcrypto"} {
+		if _, ok := payload[k]; ok {
+			t.Errorf("empty field [%s] should have been omitted", k)
+		}
+	}
+}
+
+func TestStreamingRecordingRequest_Payload(t *testing.T) {
+	f := MakeStreamingRequestFactory("")
+	start := f.StartRecordingRequest(99, "", "/tmp/audio.mjr", "", "").Payload()
+	if start["action"] != "start" || start["audio"] != "/tmp/audio.mjr" {
+		t.Errorf("unexpected start payload %v", start)
+	}
+	if _, ok := start["video"]; ok {
+		t.Errorf("video should not be recorded %v", start)
+	}
+
+	stop := f.StopRecordingRequest(99, "", true, false, false).Payload()
+	if stop["action"] != "stop" || stop["audio"] != true || stop["video"] != false {
+		t.Errorf("unexpected stop payload %v", stop)
+	}
+}
@@ -0,0 +1,188 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+// StreamingViewer is a viewer of streaming mountpoints, on top of a handle
+// attached to janus.plugin.streaming.
+type StreamingViewer struct {
+	Handle *janus.Handle
+
+	// MountpointID is set once the viewer watches a mountpoint.
+	MountpointID int
+}
+
+func NewStreamingViewer(handle *janus.Handle) *StreamingViewer {
+	return &StreamingViewer{Handle: handle}
+}
+
+type StreamingWatch struct {
+	ID         int    `json:"id"`
+	Pin        string `json:"pin,omitempty"`
+	OfferAudio *bool  `json:"offer_audio,omitempty"`
+	OfferVideo *bool  `json:"offer_video,omitempty"`
+	OfferData  *bool  `json:"offer_data,omitempty"`
+}
+
+// StreamingStatusEvent notifies a change of the status of the stream of a
+// viewer: "preparing" (with the SDP offer of the plugin in Jsep), "starting",
+// "started", "pausing", "stopping", "stopped" or "updating".
+type StreamingStatusEvent struct {
+	Status string                 `json:"status"`
+	Jsep   map[string]interface{} `json:"-"`
+}
+
+// StreamingSwitchedEvent answers a switch request, with the mountpoint now
+// watched.
+type StreamingSwitchedEvent struct {
+	Switched string `json:"switched"`
+	ID       int    `json:"id"`
+}
+
+// ParseStreamingEvent decodes the plugin data of an event received from the
+// streaming plugin to one of the Streaming*Event types, or to a
+// *StreamingErrorResponse. Unknown events are returned as the raw plugin
+// data.
+func ParseStreamingEvent(event *janus.EventMsg) (interface{}, error) {
+	data := event.Plugindata.Data
+	if _, ok := data["error"]; ok {
+		var errResp StreamingErrorResponse
+		if err := decodePluginData(data, &errResp); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal streaming event : %w", err)
+		}
+		return &errResp, nil
+	}
+
+	// The status of the viewer is nested in the result of the event.
+	result, ok := data["result"].(map[string]interface{})
+	if !ok || data["streaming"] != "event" {
+		return data, nil
+	}
+
+	var v interface{}
+	if _, ok := result["status"]; ok {
+		v = &StreamingStatusEvent{Jsep: event.Jsep}
+	} else if _, ok := result["switched"]; ok {
+		v = &StreamingSwitchedEvent{}
+	}
+	if v == nil {
+		return data, nil
+	}
+
+	if err := decodePluginData(result, v); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal streaming event : %w", err)
+	}
+	return v, nil
+}
+
+// streamingMessage sends a request to the streaming plugin through handle,
+// and parses the event answering it.
+func streamingMessage(ctx context.Context, handle *janus.Handle, request string, body interface{}, jsep map[string]interface{}) (interface{}, error) {
+	return pluginMessage(ctx, handle, request, body, jsep, &StreamingErrorResponse{}, ParseStreamingEvent)
+}
+
+// status sends a request answered with a status event.
+func (v *StreamingViewer) status(ctx context.Context, request string, body interface{}, jsep map[string]interface{}) (*StreamingStatusEvent, error) {
+	event, err := streamingMessage(ctx, v.Handle, request, body, jsep)
+	if err != nil {
+		return nil, err
+	}
+	status, ok := event.(*StreamingStatusEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to streaming %s: %v", request, event)
+	}
+	return status, nil
+}
+
+// Watch asks to watch a mountpoint.
+// On success, the preparing status event carrying the SDP offer of the
+// plugin will be returned and error will be nil. The answer is sent with
+// Start.
+func (v *StreamingViewer) Watch(watch *StreamingWatch) (*StreamingStatusEvent, error) {
+	return v.WatchContext(context.Background(), watch)
+}
+
+// WatchContext is like Watch, but gives up waiting for the response when ctx
+// is done.
+func (v *StreamingViewer) WatchContext(ctx context.Context, watch *StreamingWatch) (*StreamingStatusEvent, error) {
+	status, err := v.status(ctx, "watch", watch, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v.MountpointID = watch.ID
+	return status, nil
+}
+
+// Start starts the stream with the SDP answer of the viewer, or resumes it
+// after Pause with a nil answer.
+// On success, the starting status event will be returned and error will be
+// nil.
+func (v *StreamingViewer) Start(answer map[string]interface{}) (*StreamingStatusEvent, error) {
+	return v.StartContext(context.Background(), answer)
+}
+
+// StartContext is like Start, but gives up waiting for the response when ctx
+// is done.
+func (v *StreamingViewer) StartContext(ctx context.Context, answer map[string]interface{}) (*StreamingStatusEvent, error) {
+	return v.status(ctx, "start", nil, answer)
+}
+
+// Pause pauses the stream, until resumed with Start.
+// On success, the pausing status event will be returned and error will be
+// nil.
+func (v *StreamingViewer) Pause() (*StreamingStatusEvent, error) {
+	return v.PauseContext(context.Background())
+}
+
+// PauseContext is like Pause, but gives up waiting for the response when ctx
+// is done.
+func (v *StreamingViewer) PauseContext(ctx context.Context) (*StreamingStatusEvent, error) {
+	return v.status(ctx, "pause", nil, nil)
+}
+
+// Switch switches to another mountpoint, without renegotiating the
+// PeerConnection. The mountpoints must have the same media and codecs.
+// On success, error will be nil.
+func (v *StreamingViewer) Switch(mountpointID int) error {
+	return v.SwitchContext(context.Background(), mountpointID)
+}
+
+// SwitchContext is like Switch, but gives up waiting for the response when
+// ctx is done.
+func (v *StreamingViewer) SwitchContext(ctx context.Context, mountpointID int) error {
+	event, err := streamingMessage(ctx, v.Handle, "switch", map[string]interface{}{"id": mountpointID}, nil)
+	if err != nil {
+		return err
+	}
+	switched, ok := event.(*StreamingSwitchedEvent)
+	if !ok {
+		return fmt.Errorf("unexpected response to streaming switch: %v", event)
+	}
+
+	v.MountpointID = switched.ID
+	return nil
+}
+
+// Stop stops the stream and tears down the PeerConnection.
+// On success, the stopping status event will be returned and error will be
+// nil.
+func (v *StreamingViewer) Stop() (*StreamingStatusEvent, error) {
+	return v.StopContext(context.Background())
+}
+
+// StopContext is like Stop, but gives up waiting for the response when ctx
+// is done.
+func (v *StreamingViewer) StopContext(ctx context.Context) (*StreamingStatusEvent, error) {
+	status, err := v.status(ctx, "stop", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v.MountpointID = 0
+	return status, nil
+}
@@ -0,0 +1,116 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestStreamingViewer(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	status := func(s string, jsep map[string]interface{}) *janustest.PluginResponse {
+		return event(map[string]interface{}{"streaming": "event", "result": map[string]interface{}{"status": s}}, jsep)
+	}
+	server.HandlePlugin("janus.plugin.streaming", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		switch msg.Body["request"] {
+		case "watch":
+			if msg.Body["id"] != 1.0 {
+				return event(map[string]interface{}{"streaming": "event", "error_code": 455, "error": "No such mountpoint/stream 2"}, nil)
+			}
+			if msg.Body["offer_video"] != false {
+				t.Errorf("unexpected watch %v", msg.Body)
+			}
+			return status("preparing", map[string]interface{}{"type": "offer", "sdp": "v=0"})
+		case "start":
+			return status("starting", nil)
+		case "pause":
+			return status("pausing", nil)
+		case "switch":
+			return event(map[string]interface{}{
+				"streaming": "event",
+				"result":    map[string]interface{}{"switched": "ok", "id": msg.Body["id"]},
+			}, nil)
+		case "stop":
+			return status("stopping", nil)
+		}
+		return nil
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.streaming")
+	defer gateway.Close()
+
+	viewer := NewStreamingViewer(handle)
+	_, err := viewer.Watch(&StreamingWatch{ID: 2})
+	if perr, ok := err.(*StreamingErrorResponse); !ok || perr.Code != 455 {
+		t.Errorf("expected a StreamingErrorResponse, got %v", err)
+	}
+
+	offerVideo := false
+	preparing, err := viewer.Watch(&StreamingWatch{ID: 1, OfferVideo: &offerVideo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preparing.Status != "preparing" || preparing.Jsep["type"] != "offer" || viewer.MountpointID != 1 {
+		t.Errorf("unexpected watch response %+v", preparing)
+	}
+
+	starting, err := viewer.Start(map[string]interface{}{"type": "answer", "sdp": "v=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if starting.Status != "starting" {
+		t.Errorf("unexpected start response %+v", starting)
+	}
+
+	if pausing, err := viewer.Pause(); err != nil || pausing.Status != "pausing" {
+		t.Errorf("unexpected pause response %+v, %v", pausing, err)
+	}
+
+	if err := viewer.Switch(3); err != nil {
+		t.Fatal(err)
+	}
+	if viewer.MountpointID != 3 {
+		t.Errorf("unexpected viewer state after switch %+v", viewer)
+	}
+
+	if stopping, err := viewer.Stop(); err != nil || stopping.Status != "stopping" {
+		t.Errorf("unexpected stop response %+v, %v", stopping, err)
+	}
+	if viewer.MountpointID != 0 {
+		t.Errorf("unexpected viewer state after stop %+v", viewer)
+	}
+}
+
+func TestParseStreamingEvent(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.streaming")
+	defer gateway.Close()
+
+	push := func(data map[string]interface{}) interface{} {
+		if err := server.PushEvent(server.Sessions()[0], handle.ID, data, nil); err != nil {
+			t.Fatal(err)
+		}
+		event, err := ParseStreamingEvent(nextEvent(t, handle))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+
+	started, ok := push(map[string]interface{}{"streaming": "event", "result": map[string]interface{}{"status": "started"}}).(*StreamingStatusEvent)
+	if !ok || started.Status != "started" {
+		t.Errorf("unexpected status event %+v", started)
+	}
+
+	if _, ok := push(map[string]interface{}{"streaming": "event", "error_code": 456, "error": "Can't play"}).(*StreamingErrorResponse); !ok {
+		t.Error("expected a StreamingErrorResponse")
+	}
+
+	if _, ok := push(map[string]interface{}{"streaming": "event", "result": map[string]interface{}{"substream": 1}}).(map[string]interface{}); !ok {
+		t.Error("expected the raw plugin data")
+	}
+}
@@ -0,0 +1,258 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/xid"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+// TextroomParticipant is a participant of textrooms, on top of a handle
+// attached to janus.plugin.textroom. Requests are sent through the Janus
+// API rather than the data channel, which is only needed to receive the
+// messages and notifications of the rooms, to be decoded with
+// ParseTextroomData.
+type TextroomParticipant struct {
+	Handle *janus.Handle
+}
+
+func NewTextroomParticipant(handle *janus.Handle) *TextroomParticipant {
+	return &TextroomParticipant{Handle: handle}
+}
+
+type TextroomJoin struct {
+	Room     int    `json:"room"`
+	Username string `json:"username"`
+	Display  string `json:"display,omitempty"`
+	Pin      string `json:"pin,omitempty"`
+	Token    string `json:"token,omitempty"`
+	History  *bool  `json:"history,omitempty"`
+}
+
+// TextroomMessage is a message to a room, or a whisper to the participant To
+// or the participants Tos.
+type TextroomMessage struct {
+	Room int      `json:"room"`
+	Text string   `json:"text"`
+	To   string   `json:"to,omitempty"`
+	Tos  []string `json:"tos,omitempty"`
+	Ack  *bool    `json:"ack,omitempty"`
+}
+
+type TextroomParticipantInfo struct {
+	Username string `json:"username"`
+	Display  string `json:"display"`
+}
+
+type TextroomParticipantsResponse struct {
+	TextroomResponse
+	RoomID       int                        `json:"room"`
+	Participants []*TextroomParticipantInfo `json:"participants"`
+}
+
+type TextroomJoinEvent struct {
+	TextroomResponse
+	Room     int    `json:"room"`
+	Username string `json:"username"`
+	Display  string `json:"display"`
+}
+
+type TextroomLeaveEvent struct {
+	TextroomResponse
+	Room     int    `json:"room"`
+	Username string `json:"username"`
+}
+
+// TextroomKickedEvent notifies that a participant was kicked out of a room.
+type TextroomKickedEvent struct {
+	TextroomResponse
+	Room     int    `json:"room"`
+	Username string `json:"username"`
+}
+
+type TextroomAnnouncementEvent struct {
+	TextroomResponse
+	Room int            `json:"room"`
+	Date janus.DateTime `json:"date"`
+	Text string         `json:"text"`
+}
+
+// ParseTextroomData decodes a message received on the data channel of a
+// handle attached to the textroom plugin, which carries the messages and
+// notifications of the rooms, to a *janus.TextroomPostMsg for messages, to
+// one of the Textroom*Event types, or to a *TextroomErrorResponse. Other
+// messages, e.g. the responses to requests sent over the data channel, are
+// returned as a map.
+func ParseTextroomData(data []byte) (interface{}, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return decodeDiscriminated("textroom", textroomTypes, m)
+}
+
+// ParseTextroomEvent decodes the plugin data of an event received from the
+// textroom plugin like ParseTextroomData. The plugin reports errors as
+// events, while the messages and notifications of the rooms only go through
+// the data channel.
+func ParseTextroomEvent(event *janus.EventMsg) (interface{}, error) {
+	return textroomEventDecoder(event)
+}
+
+var textroomEventDecoder = NewEventDecoder("textroom", textroomTypes)
+
+var textroomTypes = map[string]func() interface{}{
+	"error":        func() interface{} { return &TextroomErrorResponse{} },
+	"message":      func() interface{} { return &janus.TextroomPostMsg{} },
+	"join":         func() interface{} { return &TextroomJoinEvent{} },
+	"leave":        func() interface{} { return &TextroomLeaveEvent{} },
+	"kicked":       func() interface{} { return &TextroomKickedEvent{} },
+	"announcement": func() interface{} { return &TextroomAnnouncementEvent{} },
+}
+
+func (p *TextroomParticipant) request(ctx context.Context, request string, body interface{}) (map[string]interface{}, error) {
+	payload, err := requestPayload(request, body)
+	if err != nil {
+		return nil, err
+	}
+	payload["transaction"] = xid.New().String()
+
+	return handleRequest(ctx, p.Handle, payload, &TextroomErrorResponse{})
+}
+
+// Setup asks the plugin to set up the data channel of the handle.
+// On success, the SDP offer of the plugin will be returned and error will be
+// nil. The answer is sent with Ack.
+func (p *TextroomParticipant) Setup() (map[string]interface{}, error) {
+	return p.SetupContext(context.Background())
+}
+
+// SetupContext is like Setup, but gives up waiting for the response when ctx
+// is done.
+func (p *TextroomParticipant) SetupContext(ctx context.Context) (map[string]interface{}, error) {
+	payload := map[string]interface{}{"request": "setup"}
+	event, err := handleMessage(ctx, p.Handle, payload, nil, &TextroomErrorResponse{})
+	if err != nil {
+		return nil, err
+	}
+	if event.Jsep == nil {
+		return nil, fmt.Errorf("textroom setup answered without an offer: %v", event.Plugindata.Data)
+	}
+	return event.Jsep, nil
+}
+
+// Ack completes the negotiation of the data channel with the SDP answer of
+// the participant.
+// On success, error will be nil.
+func (p *TextroomParticipant) Ack(answer map[string]interface{}) error {
+	return p.AckContext(context.Background(), answer)
+}
+
+// AckContext is like Ack, but gives up waiting for the response when ctx is
+// done.
+func (p *TextroomParticipant) AckContext(ctx context.Context, answer map[string]interface{}) error {
+	payload := map[string]interface{}{"request": "ack"}
+	_, err := handleMessage(ctx, p.Handle, payload, answer, &TextroomErrorResponse{})
+	return err
+}
+
+// Join joins a room.
+// On success, the participants of the room will be returned and error will
+// be nil.
+func (p *TextroomParticipant) Join(join *TextroomJoin) ([]*TextroomParticipantInfo, error) {
+	return p.JoinContext(context.Background(), join)
+}
+
+// JoinContext is like Join, but gives up waiting for the response when ctx
+// is done.
+func (p *TextroomParticipant) JoinContext(ctx context.Context, join *TextroomJoin) ([]*TextroomParticipantInfo, error) {
+	data, err := p.request(ctx, "join", join)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp TextroomParticipantsResponse
+	if err := decodePluginData(data, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Participants, nil
+}
+
+// Leave leaves a room.
+// On success, error will be nil.
+func (p *TextroomParticipant) Leave(room int) error {
+	return p.LeaveContext(context.Background(), room)
+}
+
+// LeaveContext is like Leave, but gives up waiting for the response when ctx
+// is done.
+func (p *TextroomParticipant) LeaveContext(ctx context.Context, room int) error {
+	_, err := p.request(ctx, "leave", map[string]interface{}{"room": room})
+	return err
+}
+
+// Message sends a message to a room, or a whisper to some of its
+// participants.
+// On success, error will be nil.
+func (p *TextroomParticipant) Message(msg *TextroomMessage) error {
+	return p.MessageContext(context.Background(), msg)
+}
+
+// MessageContext is like Message, but gives up waiting for the response when
+// ctx is done.
+func (p *TextroomParticipant) MessageContext(ctx context.Context, msg *TextroomMessage) error {
+	_, err := p.request(ctx, "message", msg)
+	return err
+}
+
+// Announcement sends an announcement to all the participants of a room.
+// On success, error will be nil.
+func (p *TextroomParticipant) Announcement(room int, secret, text string) error {
+	return p.AnnouncementContext(context.Background(), room, secret, text)
+}
+
+// AnnouncementContext is like Announcement, but gives up waiting for the
+// response when ctx is done.
+func (p *TextroomParticipant) AnnouncementContext(ctx context.Context, room int, secret, text string) error {
+	body := map[string]interface{}{"room": room, "secret": secret, "text": text}
+	_, err := p.request(ctx, "announcement", body)
+	return err
+}
+
+// Kick kicks a participant out of a room.
+// On success, error will be nil.
+func (p *TextroomParticipant) Kick(room int, secret, username string) error {
+	return p.KickContext(context.Background(), room, secret, username)
+}
+
+// KickContext is like Kick, but gives up waiting for the response when ctx
+// is done.
+func (p *TextroomParticipant) KickContext(ctx context.Context, room int, secret, username string) error {
+	body := map[string]interface{}{"room": room, "secret": secret, "username": username}
+	_, err := p.request(ctx, "kick", body)
+	return err
+}
+
+// ListParticipants lists the participants of a room.
+// On success, the participants will be returned and error will be nil.
+func (p *TextroomParticipant) ListParticipants(room int) ([]*TextroomParticipantInfo, error) {
+	return p.ListParticipantsContext(context.Background(), room)
+}
+
+// ListParticipantsContext is like ListParticipants, but gives up waiting for
+// the response when ctx is done.
+func (p *TextroomParticipant) ListParticipantsContext(ctx context.Context, room int) ([]*TextroomParticipantInfo, error) {
+	data, err := p.request(ctx, "listparticipants", map[string]interface{}{"room": room})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp TextroomParticipantsResponse
+	if err := decodePluginData(data, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Participants, nil
+}
@@ -0,0 +1,173 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go"
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestTextroomParticipant(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	participants := []interface{}{
+		map[string]interface{}{"username": "alice", "display": "Alice"},
+	}
+	server.HandlePlugin("janus.plugin.textroom", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		if msg.Body["request"] != "setup" && msg.Body["request"] != "ack" && msg.Body["transaction"] == nil {
+			t.Errorf("missing transaction in %v", msg.Body)
+		}
+		switch msg.Body["request"] {
+		case "setup":
+			return event(map[string]interface{}{"textroom": "event", "result": "ok"}, map[string]interface{}{"type": "offer", "sdp": "v=0"})
+		case "ack":
+			if msg.Jsep["type"] != "answer" {
+				t.Errorf("expected an answer, got %v", msg.Jsep)
+			}
+			return event(map[string]interface{}{"textroom": "event", "result": "ok"}, nil)
+		case "join":
+			if msg.Body["room"] != 1234.0 || msg.Body["username"] != "bob" || msg.Body["pin"] != "1111" {
+				t.Errorf("unexpected join %v", msg.Body)
+			}
+			return &janustest.PluginResponse{Data: map[string]interface{}{"textroom": "success", "participants": participants}}
+		case "message":
+			tos, _ := msg.Body["tos"].([]interface{})
+			if msg.Body["text"] != "hi" || len(tos) != 2 || msg.Body["ack"] != false {
+				t.Errorf("unexpected message %v", msg.Body)
+			}
+			return &janustest.PluginResponse{Data: map[string]interface{}{"textroom": "success"}}
+		case "announcement":
+			if msg.Body["secret"] != "adminpwd" {
+				return &janustest.PluginResponse{Data: map[string]interface{}{"textroom": "error", "error_code": 419, "error": "Unauthorized (wrong secret)"}}
+			}
+			return &janustest.PluginResponse{Data: map[string]interface{}{"textroom": "success"}}
+		case "listparticipants":
+			return &janustest.PluginResponse{Data: map[string]interface{}{"textroom": "success", "room": 1234, "participants": participants}}
+		case "kick", "leave":
+			return &janustest.PluginResponse{Data: map[string]interface{}{"textroom": "success"}}
+		}
+		return nil
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.textroom")
+	defer gateway.Close()
+
+	participant := NewTextroomParticipant(handle)
+	offer, err := participant.Setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offer["type"] != "offer" {
+		t.Errorf("expected an offer, got %v", offer)
+	}
+	if err := participant.Ack(map[string]interface{}{"type": "answer", "sdp": "v=0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	joined, err := participant.Join(&TextroomJoin{Room: 1234, Username: "bob", Pin: "1111"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(joined) != 1 || joined[0].Username != "alice" || joined[0].Display != "Alice" {
+		t.Errorf("unexpected participants %v", joined)
+	}
+
+	ack := false
+	if err := participant.Message(&TextroomMessage{Room: 1234, Text: "hi", Tos: []string{"alice", "carol"}, Ack: &ack}); err != nil {
+		t.Error(err)
+	}
+
+	err = participant.Announcement(1234, "wrong", "hello")
+	if perr, ok := err.(*TextroomErrorResponse); !ok || perr.Code != 419 {
+		t.Errorf("expected a TextroomErrorResponse, got %v", err)
+	}
+	if err := participant.Announcement(1234, "adminpwd", "hello"); err != nil {
+		t.Error(err)
+	}
+
+	listed, err := participant.ListParticipants(1234)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 {
+		t.Errorf("unexpected participants %v", listed)
+	}
+
+	if err := participant.Kick(1234, "adminpwd", "alice"); err != nil {
+		t.Error(err)
+	}
+	if err := participant.Leave(1234); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTextroomParticipant_NilBody(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	server.HandlePlugin("janus.plugin.textroom", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		if msg.Body["transaction"] == nil || len(msg.Body) != 2 {
+			t.Errorf("unexpected %v request %v", msg.Body["request"], msg.Body)
+		}
+		return &janustest.PluginResponse{Data: map[string]interface{}{"textroom": "success"}}
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.textroom")
+	defer gateway.Close()
+
+	// nil request structs are sent as requests without parameters
+	participant := NewTextroomParticipant(handle)
+	if _, err := participant.Join(nil); err != nil {
+		t.Error(err)
+	}
+	if err := participant.Message(nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseTextroomData(t *testing.T) {
+	parse := func(data string) interface{} {
+		event, err := ParseTextroomData([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+
+	msg, ok := parse(`{"textroom":"message","room":1234,"from":"alice","date":"2021-03-04T05:06:07+0100","text":"psst","whisper":true}`).(*janus.TextroomPostMsg)
+	if !ok || msg.From != "alice" || msg.Text != "psst" || !msg.Whisper {
+		t.Errorf("unexpected message %+v", msg)
+	}
+
+	join, ok := parse(`{"textroom":"join","room":1234,"username":"carol","display":"Carol"}`).(*TextroomJoinEvent)
+	if !ok || join.Username != "carol" || join.Display != "Carol" {
+		t.Errorf("unexpected join notification %+v", join)
+	}
+
+	leave, ok := parse(`{"textroom":"leave","room":1234,"username":"carol"}`).(*TextroomLeaveEvent)
+	if !ok || leave.Username != "carol" {
+		t.Errorf("unexpected leave notification %+v", leave)
+	}
+
+	announcement, ok := parse(`{"textroom":"announcement","room":1234,"date":"2021-03-04T05:06:07+0100","text":"closing"}`).(*TextroomAnnouncementEvent)
+	if !ok || announcement.Text != "closing" {
+		t.Errorf("unexpected announcement %+v", announcement)
+	}
+
+	if _, ok := parse(`{"textroom":"kicked","room":1234,"username":"bob"}`).(*TextroomKickedEvent); !ok {
+		t.Error("expected a TextroomKickedEvent")
+	}
+
+	if errResp, ok := parse(`{"textroom":"error","transaction":"abc","error_code":417,"error":"No such room"}`).(*TextroomErrorResponse); !ok || errResp.Code != 417 {
+		t.Errorf("unexpected error response %+v", errResp)
+	}
+
+	if _, ok := parse(`{"textroom":"success","transaction":"abc"}`).(map[string]interface{}); !ok {
+		t.Error("expected the raw message")
+	}
+
+	if _, err := ParseTextroomData([]byte(`not json`)); err == nil {
+		t.Error("expected an error for a malformed message")
+	}
+}
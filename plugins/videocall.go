@@ -0,0 +1,254 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+type VideocallResponse struct {
+	Videocall string `json:"videocall"`
+}
+
+type VideocallErrorResponse struct {
+	VideocallResponse
+	PluginError
+}
+
+func (err *VideocallErrorResponse) Error() string {
+	return err.PluginError.Error()
+}
+
+// VideocallClient is a user of the videocall plugin, calling other users by
+// their username, on top of a handle attached to janus.plugin.videocall.
+type VideocallClient struct {
+	Handle *janus.Handle
+
+	// Username is set once the user registered.
+	Username string
+}
+
+func NewVideocallClient(handle *janus.Handle) *VideocallClient {
+	return &VideocallClient{Handle: handle}
+}
+
+// VideocallSet holds the settings of the media sent to the peer. Substream
+// and Temporal select the simulcast layers, SpatialLayer and TemporalLayer
+// the SVC layers. nil and empty fields are left unchanged.
+type VideocallSet struct {
+	Audio         *bool  `json:"audio,omitempty"`
+	Video         *bool  `json:"video,omitempty"`
+	Bitrate       int    `json:"bitrate,omitempty"`
+	Record        *bool  `json:"record,omitempty"`
+	Filename      string `json:"filename,omitempty"`
+	Substream     *int   `json:"substream,omitempty"`
+	Temporal      *int   `json:"temporal,omitempty"`
+	Fallback      int    `json:"fallback,omitempty"`
+	SpatialLayer  *int   `json:"spatial_layer,omitempty"`
+	TemporalLayer *int   `json:"temporal_layer,omitempty"`
+}
+
+// VideocallEvent is an event of the videocall plugin without a dedicated
+// type, e.g. the acknowledgement of a request ("registered", "calling",
+// "set"...).
+type VideocallEvent struct {
+	Event    string                 `json:"event"`
+	Username string                 `json:"username"`
+	Result   map[string]interface{} `json:"-"`
+	Jsep     map[string]interface{} `json:"-"`
+}
+
+type VideocallListEvent struct {
+	List []string `json:"list"`
+}
+
+// VideocallIncomingCallEvent notifies a call from Username, with the SDP
+// offer of the caller in Jsep.
+type VideocallIncomingCallEvent struct {
+	Username string                 `json:"username"`
+	Jsep     map[string]interface{} `json:"-"`
+}
+
+// VideocallAcceptedEvent notifies that the peer Username accepted the call,
+// with its SDP answer in Jsep. The callee receives it without answer.
+type VideocallAcceptedEvent struct {
+	Username string                 `json:"username"`
+	Jsep     map[string]interface{} `json:"-"`
+}
+
+// VideocallUpdateEvent notifies a renegotiation of the PeerConnection by the
+// peer, with the new SDP offer or answer in Jsep.
+type VideocallUpdateEvent struct {
+	Jsep map[string]interface{} `json:"-"`
+}
+
+type VideocallHangupEvent struct {
+	Username string `json:"username"`
+	Reason   string `json:"reason"`
+}
+
+// ParseVideocallEvent decodes the plugin data of an event received from the
+// videocall plugin to one of the Videocall*Event types, to a
+// *VideocallEvent for the events without a dedicated type, or to a
+// *VideocallErrorResponse. Other plugin data is returned as is.
+func ParseVideocallEvent(event *janus.EventMsg) (interface{}, error) {
+	data := event.Plugindata.Data
+	if _, ok := data["error"]; ok {
+		var errResp VideocallErrorResponse
+		if err := decodePluginData(data, &errResp); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal videocall event : %w", err)
+		}
+		return &errResp, nil
+	}
+
+	result, ok := data["result"].(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+
+	var v interface{}
+	if _, ok := result["list"]; ok {
+		v = &VideocallListEvent{}
+	} else {
+		switch result["event"] {
+		case "incomingcall":
+			v = &VideocallIncomingCallEvent{Jsep: event.Jsep}
+		case "accepted":
+			v = &VideocallAcceptedEvent{Jsep: event.Jsep}
+		case "update":
+			v = &VideocallUpdateEvent{Jsep: event.Jsep}
+		case "hangup":
+			v = &VideocallHangupEvent{}
+		default:
+			v = &VideocallEvent{Result: result, Jsep: event.Jsep}
+		}
+	}
+
+	if err := decodePluginData(result, v); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal videocall event : %w", err)
+	}
+	return v, nil
+}
+
+// videocallMessage sends a request to the videocall plugin through handle,
+// and parses the event answering it.
+func videocallMessage(ctx context.Context, handle *janus.Handle, request string, body interface{}, jsep map[string]interface{}) (interface{}, error) {
+	return pluginMessage(ctx, handle, request, body, jsep, &VideocallErrorResponse{}, ParseVideocallEvent)
+}
+
+// expect sends a request the plugin acknowledges with the event ack.
+func (c *VideocallClient) expect(ctx context.Context, request string, body interface{}, jsep map[string]interface{}, ack string) (*VideocallEvent, error) {
+	event, err := videocallMessage(ctx, c.Handle, request, body, jsep)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := event.(*VideocallEvent)
+	if !ok || e.Event != ack {
+		return nil, fmt.Errorf("unexpected response to videocall %s: %v", request, event)
+	}
+	return e, nil
+}
+
+// List lists the registered users.
+// On success, their usernames will be returned and error will be nil.
+func (c *VideocallClient) List() ([]string, error) {
+	return c.ListContext(context.Background())
+}
+
+// ListContext is like List, but gives up waiting for the response when ctx
+// is done.
+func (c *VideocallClient) ListContext(ctx context.Context) ([]string, error) {
+	event, err := videocallMessage(ctx, c.Handle, "list", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := event.(*VideocallListEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to videocall list: %v", event)
+	}
+	return list.List, nil
+}
+
+// Register registers the user as username.
+// On success, error will be nil.
+func (c *VideocallClient) Register(username string) error {
+	return c.RegisterContext(context.Background(), username)
+}
+
+// RegisterContext is like Register, but gives up waiting for the response
+// when ctx is done.
+func (c *VideocallClient) RegisterContext(ctx context.Context, username string) error {
+	registered, err := c.expect(ctx, "register", map[string]interface{}{"username": username}, nil, "registered")
+	if err != nil {
+		return err
+	}
+
+	c.Username = registered.Username
+	return nil
+}
+
+// Call calls the user username with the SDP offer of the user.
+// On success, error will be nil. The answer of the peer is notified by a
+// VideocallAcceptedEvent, or a refusal by a VideocallHangupEvent.
+func (c *VideocallClient) Call(username string, offer map[string]interface{}) error {
+	return c.CallContext(context.Background(), username, offer)
+}
+
+// CallContext is like Call, but gives up waiting for the response when ctx
+// is done.
+func (c *VideocallClient) CallContext(ctx context.Context, username string, offer map[string]interface{}) error {
+	_, err := c.expect(ctx, "call", map[string]interface{}{"username": username}, offer, "calling")
+	return err
+}
+
+// Accept accepts an incoming call with the SDP answer of the user.
+// On success, error will be nil.
+func (c *VideocallClient) Accept(answer map[string]interface{}) error {
+	return c.AcceptContext(context.Background(), answer)
+}
+
+// AcceptContext is like Accept, but gives up waiting for the response when
+// ctx is done.
+func (c *VideocallClient) AcceptContext(ctx context.Context, answer map[string]interface{}) error {
+	event, err := videocallMessage(ctx, c.Handle, "accept", nil, answer)
+	if err != nil {
+		return err
+	}
+	if _, ok := event.(*VideocallAcceptedEvent); !ok {
+		return fmt.Errorf("unexpected response to videocall accept: %v", event)
+	}
+	return nil
+}
+
+// Set changes the settings of the media sent to the peer. jsep can carry an
+// SDP offer to renegotiate the PeerConnection, the answer of the plugin is
+// in the Jsep field of the returned event.
+func (c *VideocallClient) Set(set *VideocallSet, jsep map[string]interface{}) (*VideocallEvent, error) {
+	return c.SetContext(context.Background(), set, jsep)
+}
+
+// SetContext is like Set, but gives up waiting for the response when ctx is
+// done.
+func (c *VideocallClient) SetContext(ctx context.Context, set *VideocallSet, jsep map[string]interface{}) (*VideocallEvent, error) {
+	return c.expect(ctx, "set", set, jsep, "set")
+}
+
+// Hangup hangs up the call.
+// On success, error will be nil.
+func (c *VideocallClient) Hangup() error {
+	return c.HangupContext(context.Background())
+}
+
+// HangupContext is like Hangup, but gives up waiting for the response when
+// ctx is done.
+func (c *VideocallClient) HangupContext(ctx context.Context) error {
+	event, err := videocallMessage(ctx, c.Handle, "hangup", nil, nil)
+	if err != nil {
+		return err
+	}
+	if _, ok := event.(*VideocallHangupEvent); !ok {
+		return fmt.Errorf("unexpected response to videocall hangup: %v", event)
+	}
+	return nil
+}
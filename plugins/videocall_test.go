@@ -0,0 +1,118 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestVideocallClient(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	result := func(fields map[string]interface{}, jsep map[string]interface{}) *janustest.PluginResponse {
+		return event(map[string]interface{}{"videocall": "event", "result": fields}, jsep)
+	}
+	server.HandlePlugin("janus.plugin.videocall", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		switch msg.Body["request"] {
+		case "list":
+			return result(map[string]interface{}{"list": []interface{}{"alice", "bob"}}, nil)
+		case "register":
+			if msg.Body["username"] == "alice" {
+				return event(map[string]interface{}{"videocall": "event", "error_code": 476, "error": "Username 'alice' already taken"}, nil)
+			}
+			return result(map[string]interface{}{"event": "registered", "username": msg.Body["username"]}, nil)
+		case "call":
+			if msg.Body["username"] != "alice" || msg.Jsep["type"] != "offer" {
+				t.Errorf("unexpected call %v %v", msg.Body, msg.Jsep)
+			}
+			return result(map[string]interface{}{"event": "calling"}, nil)
+		case "accept":
+			return result(map[string]interface{}{"event": "accepted"}, nil)
+		case "set":
+			if msg.Body["bitrate"] != 256000.0 {
+				t.Errorf("unexpected set %v", msg.Body)
+			}
+			return result(map[string]interface{}{"event": "set"}, nil)
+		case "hangup":
+			return result(map[string]interface{}{"event": "hangup", "username": "alice", "reason": "Explicit hangup"}, nil)
+		}
+		return nil
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.videocall")
+	defer gateway.Close()
+
+	client := NewVideocallClient(handle)
+	list, err := client.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[1] != "bob" {
+		t.Errorf("unexpected list %v", list)
+	}
+
+	err = client.Register("alice")
+	if perr, ok := err.(*VideocallErrorResponse); !ok || perr.Code != 476 {
+		t.Errorf("expected a VideocallErrorResponse, got %v", err)
+	}
+	if err := client.Register("carol"); err != nil {
+		t.Fatal(err)
+	}
+	if client.Username != "carol" {
+		t.Errorf("unexpected client state %+v", client)
+	}
+
+	if err := client.Call("alice", map[string]interface{}{"type": "offer", "sdp": "v=0"}); err != nil {
+		t.Error(err)
+	}
+	if err := client.Accept(map[string]interface{}{"type": "answer", "sdp": "v=0"}); err != nil {
+		t.Error(err)
+	}
+	if _, err := client.Set(&VideocallSet{Bitrate: 256000}, nil); err != nil {
+		t.Error(err)
+	}
+	if err := client.Hangup(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseVideocallEvent(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.videocall")
+	defer gateway.Close()
+
+	push := func(result map[string]interface{}, jsep map[string]interface{}) interface{} {
+		data := map[string]interface{}{"videocall": "event", "result": result}
+		if err := server.PushEvent(server.Sessions()[0], handle.ID, data, jsep); err != nil {
+			t.Fatal(err)
+		}
+		event, err := ParseVideocallEvent(nextEvent(t, handle))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+
+	incoming, ok := push(map[string]interface{}{"event": "incomingcall", "username": "alice"}, map[string]interface{}{"type": "offer", "sdp": "v=0"}).(*VideocallIncomingCallEvent)
+	if !ok || incoming.Username != "alice" || incoming.Jsep["type"] != "offer" {
+		t.Errorf("unexpected incomingcall event %+v", incoming)
+	}
+
+	accepted, ok := push(map[string]interface{}{"event": "accepted", "username": "alice"}, map[string]interface{}{"type": "answer", "sdp": "v=0"}).(*VideocallAcceptedEvent)
+	if !ok || accepted.Username != "alice" || accepted.Jsep["type"] != "answer" {
+		t.Errorf("unexpected accepted event %+v", accepted)
+	}
+
+	update, ok := push(map[string]interface{}{"event": "update"}, map[string]interface{}{"type": "offer", "sdp": "v=0"}).(*VideocallUpdateEvent)
+	if !ok || update.Jsep["type"] != "offer" {
+		t.Errorf("unexpected update event %+v", update)
+	}
+
+	hangup, ok := push(map[string]interface{}{"event": "hangup", "username": "alice", "reason": "Remote WebRTC hangup"}, nil).(*VideocallHangupEvent)
+	if !ok || hangup.Reason != "Remote WebRTC hangup" {
+		t.Errorf("unexpected hangup event %+v", hangup)
+	}
+}
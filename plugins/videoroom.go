@@ -1,6 +1,9 @@
 package plugins
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/tatsujin1/janus-go"
 )
 
@@ -191,3 +194,66 @@ func (r *VideoroomRoomForEdit) AsMap() map[string]interface{} {
 	m, _ := janus.StructToMap(r)
 	return m
 }
+
+// ParseVideoroomEvent decodes the plugin data of an event received from the
+// videoroom plugin to one of the Videoroom*Event types, or to a
+// *VideoroomErrorResponse. Unknown events are returned as the raw plugin
+// data.
+func ParseVideoroomEvent(event *janus.EventMsg) (interface{}, error) {
+	data := event.Plugindata.Data
+
+	var v interface{}
+	switch data["videoroom"] {
+	case "joined":
+		v = &VideoroomJoinedEvent{Jsep: event.Jsep}
+	case "attached":
+		v = &VideoroomAttachedEvent{Jsep: event.Jsep}
+	case "updated":
+		v = &VideoroomUpdatedEvent{Jsep: event.Jsep}
+	case "talking", "stopped-talking":
+		v = &VideoroomTalkingEvent{Talking: data["videoroom"] == "talking"}
+	case "event":
+		if _, ok := data["error"]; ok {
+			v = &VideoroomErrorResponse{}
+		} else if _, ok := data["configured"]; ok {
+			v = &VideoroomConfiguredEvent{Jsep: event.Jsep}
+		} else if _, ok := data["publishers"]; ok {
+			v = &VideoroomPublishersEvent{}
+		} else if feed, ok := data["unpublished"]; ok {
+			v = &VideoroomUnpublishedEvent{ID: feedID(feed)}
+		} else if feed, ok := data["leaving"]; ok {
+			v = &VideoroomLeavingEvent{ID: feedID(feed)}
+		} else if _, ok := data["started"]; ok {
+			v = &VideoroomStartedEvent{}
+		} else if _, ok := data["paused"]; ok {
+			v = &VideoroomPausedEvent{}
+		} else if _, ok := data["switched"]; ok {
+			v = &VideoroomSwitchedEvent{}
+		} else if _, ok := data["left"]; ok {
+			v = &VideoroomLeftEvent{}
+		}
+	}
+	if v == nil {
+		return data, nil
+	}
+
+	if err := decodePluginData(data, v); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal videoroom event : %w", err)
+	}
+	return v, nil
+}
+
+// feedID returns the publisher ID of unpublished and leaving events, which
+// carry "ok" instead when they concern the receiving handle.
+func feedID(feed interface{}) uint64 {
+	if id, ok := feed.(float64); ok {
+		return uint64(id)
+	}
+	return 0
+}
+
+// videoroomMessage sends a request to the videoroom plugin through handle,
+// and parses the event answering it.
+func videoroomMessage(ctx context.Context, handle *janus.Handle, request string, body interface{}, jsep map[string]interface{}) (interface{}, error) {
+	return pluginMessage(ctx, handle, request, body, jsep, &VideoroomErrorResponse{}, ParseVideoroomEvent)
+}
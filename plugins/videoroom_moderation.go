@@ -0,0 +1,315 @@
+package plugins
+
+func (f *VideoroomRequestFactory) ExistsRequest(roomID int) *VideoroomRoomRequest {
+	return &VideoroomRoomRequest{
+		BasePluginRequest: f.make("exists"),
+		RoomID:            roomID,
+	}
+}
+
+// AllowedRequest changes the tokens allowed to join a room. action is one of
+// "enable", "disable", "add" or "remove", allowed is ignored by the first two.
+func (f *VideoroomRequestFactory) AllowedRequest(roomID int, secret, action string, allowed []string) *VideoroomAllowedRequest {
+	return &VideoroomAllowedRequest{
+		BasePluginRequest: f.make("allowed"),
+		RoomID:            roomID,
+		Secret:            secret,
+		AllowedAction:     action,
+		Allowed:           allowed,
+	}
+}
+
+func (f *VideoroomRequestFactory) KickRequest(roomID int, secret string, participantID uint64) *VideoroomKickRequest {
+	return &VideoroomKickRequest{
+		BasePluginRequest: f.make("kick"),
+		RoomID:            roomID,
+		Secret:            secret,
+		ParticipantID:     participantID,
+	}
+}
+
+// ModerateRequest mutes or unmutes the stream mid of a publisher.
+func (f *VideoroomRequestFactory) ModerateRequest(roomID int, secret string, participantID uint64, mid string, mute bool) *VideoroomModerateRequest {
+	return &VideoroomModerateRequest{
+		BasePluginRequest: f.make("moderate"),
+		RoomID:            roomID,
+		Secret:            secret,
+		ParticipantID:     participantID,
+		Mid:               mid,
+		Mute:              mute,
+	}
+}
+
+func (f *VideoroomRequestFactory) ListParticipantsRequest(roomID int) *VideoroomRoomRequest {
+	return &VideoroomRoomRequest{
+		BasePluginRequest: f.make("listparticipants"),
+		RoomID:            roomID,
+	}
+}
+
+func (f *VideoroomRequestFactory) ListForwardersRequest(roomID int, secret string) *VideoroomRoomRequest {
+	return &VideoroomRoomRequest{
+		BasePluginRequest: f.make("listforwarders"),
+		RoomID:            roomID,
+		Secret:            secret,
+	}
+}
+
+func (f *VideoroomRequestFactory) RtpForwardRequest(roomID int, secret string, publisherID uint64, host string, streams []*VideoroomForwardStream) *VideoroomRtpForwardRequest {
+	return &VideoroomRtpForwardRequest{
+		BasePluginRequest: f.make("rtp_forward"),
+		RoomID:            roomID,
+		Secret:            secret,
+		PublisherID:       publisherID,
+		Host:              host,
+		Streams:           streams,
+	}
+}
+
+func (f *VideoroomRequestFactory) StopRtpForwardRequest(roomID int, secret string, publisherID, streamID uint64) *VideoroomStopRtpForwardRequest {
+	return &VideoroomStopRtpForwardRequest{
+		BasePluginRequest: f.make("stop_rtp_forward"),
+		RoomID:            roomID,
+		Secret:            secret,
+		PublisherID:       publisherID,
+		StreamID:          streamID,
+	}
+}
+
+func (f *VideoroomRequestFactory) EnableRecordingRequest(roomID int, secret string, record bool) *VideoroomEnableRecordingRequest {
+	return &VideoroomEnableRecordingRequest{
+		BasePluginRequest: f.make("enable_recording"),
+		RoomID:            roomID,
+		Secret:            secret,
+		Record:            record,
+	}
+}
+
+// VideoroomRoomRequest is a request about a room, which takes no other
+// parameter than the room secret.
+type VideoroomRoomRequest struct {
+	BasePluginRequest
+	RoomID int
+	Secret string
+}
+
+func (r *VideoroomRoomRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type VideoroomSuccessResponse struct {
+	VideoroomResponse
+}
+
+type VideoroomExistsResponse struct {
+	VideoroomResponse
+	RoomID int  `json:"room"`
+	Exists bool `json:"exists"`
+}
+
+type VideoroomAllowedRequest struct {
+	BasePluginRequest
+	RoomID        int
+	Secret        string
+	AllowedAction string
+	Allowed       []string
+}
+
+func (r *VideoroomAllowedRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["action"] = r.AllowedAction
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	if len(r.Allowed) > 0 {
+		payload["allowed"] = r.Allowed
+	}
+	return payload
+}
+
+type VideoroomAllowedResponse struct {
+	VideoroomResponse
+	RoomID  int      `json:"room"`
+	Allowed []string `json:"allowed"`
+}
+
+type VideoroomKickRequest struct {
+	BasePluginRequest
+	RoomID        int
+	Secret        string
+	ParticipantID uint64
+}
+
+func (r *VideoroomKickRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["id"] = r.ParticipantID
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type VideoroomModerateRequest struct {
+	BasePluginRequest
+	RoomID        int
+	Secret        string
+	ParticipantID uint64
+	Mid           string
+	Mute          bool
+}
+
+func (r *VideoroomModerateRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["id"] = r.ParticipantID
+	payload["mid"] = r.Mid
+	payload["mute"] = r.Mute
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type VideoroomParticipant struct {
+	ID        uint64 `json:"id"`
+	Display   string `json:"display"`
+	Publisher bool   `json:"publisher"`
+	Talking   bool   `json:"talking"`
+}
+
+type VideoroomParticipantsResponse struct {
+	VideoroomResponse
+	RoomID       int                     `json:"room"`
+	Participants []*VideoroomParticipant `json:"participants"`
+}
+
+type VideoroomForwarder struct {
+	StreamID       uint64 `json:"stream_id"`
+	Type           string `json:"type"`
+	Host           string `json:"host"`
+	Port           int    `json:"port"`
+	LocalRtcpPort  int    `json:"local_rtcp_port"`
+	RemoteRtcpPort int    `json:"remote_rtcp_port"`
+	Ssrc           uint32 `json:"ssrc"`
+	PT             int    `json:"pt"`
+	Substream      int    `json:"substream"`
+	Srtp           bool   `json:"srtp"`
+}
+
+type VideoroomPublisherForwarders struct {
+	PublisherID uint64                `json:"publisher_id"`
+	Forwarders  []*VideoroomForwarder `json:"forwarders"`
+}
+
+type VideoroomForwardersResponse struct {
+	VideoroomResponse
+	RoomID     int                             `json:"room"`
+	Publishers []*VideoroomPublisherForwarders `json:"publishers"`
+}
+
+// VideoroomForwardStream is a stream of a publisher to forward over RTP.
+// Host overrides the host of the request for this stream.
+type VideoroomForwardStream struct {
+	Mid       string `json:"mid"`
+	Host      string `json:"host,omitempty"`
+	Port      int    `json:"port"`
+	RtcpPort  int    `json:"rtcp_port,omitempty"`
+	Ssrc      uint32 `json:"ssrc,omitempty"`
+	PT        int    `json:"pt,omitempty"`
+	Simulcast bool   `json:"simulcast,omitempty"`
+	Port2     int    `json:"port_2,omitempty"`
+	Port3     int    `json:"port_3,omitempty"`
+}
+
+type VideoroomRtpForwardRequest struct {
+	BasePluginRequest
+	RoomID      int
+	Secret      string
+	PublisherID uint64
+	Host        string
+	HostFamily  string
+	Streams     []*VideoroomForwardStream
+	SrtpSuite   int
+	SrtpCrypto  string
+}
+
+func (r *VideoroomRtpForwardRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["publisher_id"] = r.PublisherID
+	payload["host"] = r.Host
+	payload["streams"] = r.Streams
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	if r.HostFamily != "" {
+		payload["host_family"] = r.HostFamily
+	}
+	if r.SrtpCrypto != "" {
+		payload["srtp_suite"] = r.SrtpSuite
+		payload["srtp_crypto"] = r.SrtpCrypto
+	}
+	return payload
+}
+
+type VideoroomRtpForwardResponse struct {
+	VideoroomResponse
+	RoomID      int                   `json:"room"`
+	PublisherID uint64                `json:"publisher_id"`
+	Forwarders  []*VideoroomForwarder `json:"forwarders"`
+}
+
+type VideoroomStopRtpForwardRequest struct {
+	BasePluginRequest
+	RoomID      int
+	Secret      string
+	PublisherID uint64
+	StreamID    uint64
+}
+
+func (r *VideoroomStopRtpForwardRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["publisher_id"] = r.PublisherID
+	payload["stream_id"] = r.StreamID
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type VideoroomStopRtpForwardResponse struct {
+	VideoroomResponse
+	RoomID      int    `json:"room"`
+	PublisherID uint64 `json:"publisher_id"`
+	StreamID    uint64 `json:"stream_id"`
+}
+
+type VideoroomEnableRecordingRequest struct {
+	BasePluginRequest
+	RoomID int
+	Secret string
+	Record bool
+}
+
+func (r *VideoroomEnableRecordingRequest) Payload() map[string]interface{} {
+	payload := r.BasePluginRequest.Payload()
+	payload["room"] = r.RoomID
+	payload["record"] = r.Record
+	if r.Secret != "" {
+		payload["secret"] = r.Secret
+	}
+	return payload
+}
+
+type VideoroomEnableRecordingResponse struct {
+	VideoroomResponse
+	Record bool `json:"record"`
+}
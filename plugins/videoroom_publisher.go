@@ -0,0 +1,290 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+// VideoroomPublisher is a participant of a videoroom in the publisher role,
+// on top of a handle attached to janus.plugin.videoroom.
+type VideoroomPublisher struct {
+	Handle *janus.Handle
+
+	// Room, ID and PrivateID are set once the publisher joined a room.
+	Room      int
+	ID        uint64
+	PrivateID uint64
+}
+
+func NewVideoroomPublisher(handle *janus.Handle) *VideoroomPublisher {
+	return &VideoroomPublisher{Handle: handle}
+}
+
+type VideoroomPublisherJoin struct {
+	Room    int    `json:"room"`
+	ID      uint64 `json:"id,omitempty"`
+	Display string `json:"display,omitempty"`
+	Token   string `json:"token,omitempty"`
+	Pin     string `json:"pin,omitempty"`
+}
+
+// VideoroomPublisherConfigure holds the settings of publish, configure and
+// joinandconfigure requests. nil and empty fields are left unchanged.
+// AudioCodec and VideoCodec force the codecs negotiated when publishing,
+// Streams change the settings of single published streams, and
+// Descriptions name them.
+type VideoroomPublisherConfigure struct {
+	AudioCodec         string                               `json:"audiocodec,omitempty"`
+	VideoCodec         string                               `json:"videocodec,omitempty"`
+	Bitrate            int                                  `json:"bitrate,omitempty"`
+	Record             *bool                                `json:"record,omitempty"`
+	Filename           string                               `json:"filename,omitempty"`
+	Display            string                               `json:"display,omitempty"`
+	AudioActivePackets int                                  `json:"audio_active_packets,omitempty"`
+	AudioLevelAverage  int                                  `json:"audio_level_average,omitempty"`
+	Keyframe           bool                                 `json:"keyframe,omitempty"`
+	Streams            []*VideoroomPublisherStreamConfigure `json:"streams,omitempty"`
+	Descriptions       []*VideoroomStreamDescription        `json:"descriptions,omitempty"`
+}
+
+// VideoroomPublisherStreamConfigure changes the settings of the published
+// stream Mid. Send false stops relaying it to the subscribers.
+type VideoroomPublisherStreamConfigure struct {
+	Mid      string `json:"mid"`
+	Keyframe bool   `json:"keyframe,omitempty"`
+	Send     *bool  `json:"send,omitempty"`
+	MinDelay int    `json:"min_delay,omitempty"`
+	MaxDelay int    `json:"max_delay,omitempty"`
+}
+
+// VideoroomStreamDescription describes the published stream Mid, e.g.
+// "webcam" or "screen".
+type VideoroomStreamDescription struct {
+	Mid         string `json:"mid"`
+	Description string `json:"description"`
+}
+
+// VideoroomPublisherInfo is a publisher of a room, with the streams it
+// publishes. Subscribers select the streams to receive by their Mid.
+type VideoroomPublisherInfo struct {
+	ID      uint64                      `json:"id"`
+	Display string                      `json:"display"`
+	Dummy   bool                        `json:"dummy"`
+	Talking bool                        `json:"talking"`
+	Streams []*VideoroomPublisherStream `json:"streams"`
+}
+
+// VideoroomPublisherStream is a stream published by a publisher. Type is
+// "audio", "video" or "data".
+type VideoroomPublisherStream struct {
+	Type        string `json:"type"`
+	Mindex      int    `json:"mindex"`
+	Mid         string `json:"mid"`
+	Disabled    bool   `json:"disabled"`
+	Codec       string `json:"codec"`
+	Description string `json:"description"`
+	Moderated   bool   `json:"moderated"`
+	Simulcast   bool   `json:"simulcast"`
+	Svc         bool   `json:"svc"`
+	Talking     bool   `json:"talking"`
+}
+
+type VideoroomAttendee struct {
+	ID      uint64 `json:"id"`
+	Display string `json:"display"`
+}
+
+type VideoroomJoinedEvent struct {
+	VideoroomResponse
+	Room        int                       `json:"room"`
+	Description string                    `json:"description"`
+	ID          uint64                    `json:"id"`
+	PrivateID   uint64                    `json:"private_id"`
+	Publishers  []*VideoroomPublisherInfo `json:"publishers"`
+	Attendees   []*VideoroomAttendee      `json:"attendees"`
+	Jsep        map[string]interface{}    `json:"-"`
+}
+
+// VideoroomConfiguredEvent answers publish and configure requests, listing
+// the streams published once negotiated.
+type VideoroomConfiguredEvent struct {
+	VideoroomResponse
+	Room       int                         `json:"room"`
+	Configured string                      `json:"configured"`
+	Streams    []*VideoroomPublisherStream `json:"streams"`
+	Jsep       map[string]interface{}      `json:"-"`
+}
+
+// VideoroomPublishersEvent announces new publishers in the room.
+type VideoroomPublishersEvent struct {
+	VideoroomResponse
+	Room       int                       `json:"room"`
+	Publishers []*VideoroomPublisherInfo `json:"publishers"`
+}
+
+// VideoroomUnpublishedEvent notifies that a publisher stopped publishing.
+// ID is 0 when it is the receiving handle which unpublished.
+type VideoroomUnpublishedEvent struct {
+	VideoroomResponse
+	Room int    `json:"room"`
+	ID   uint64 `json:"-"`
+}
+
+// VideoroomLeavingEvent notifies that a participant left the room.
+// ID is 0 when it is the receiving handle which left (e.g. it was kicked).
+type VideoroomLeavingEvent struct {
+	VideoroomResponse
+	Room   int    `json:"room"`
+	ID     uint64 `json:"-"`
+	Reason string `json:"reason"`
+}
+
+// VideoroomTalkingEvent notifies that a publisher started (talking) or
+// stopped (stopped-talking) talking, in rooms with audiolevel_event enabled.
+type VideoroomTalkingEvent struct {
+	VideoroomResponse
+	Room       int     `json:"room"`
+	ID         uint64  `json:"id"`
+	AudioLevel float64 `json:"audio-level-dBov-avg"`
+	Talking    bool    `json:"-"`
+}
+
+// Join joins a room as a publisher, without publishing yet.
+// On success, the joined event listing the current publishers will be
+// returned and error will be nil.
+func (p *VideoroomPublisher) Join(join *VideoroomPublisherJoin) (*VideoroomJoinedEvent, error) {
+	return p.JoinContext(context.Background(), join)
+}
+
+// JoinContext is like Join, but gives up waiting for the response when ctx
+// is done.
+func (p *VideoroomPublisher) JoinContext(ctx context.Context, join *VideoroomPublisherJoin) (*VideoroomJoinedEvent, error) {
+	return p.join(ctx, "join", join, nil, nil)
+}
+
+// JoinAndConfigure joins a room as a publisher and publishes at once.
+// offer should be the SDP offer of the publisher, the SDP answer of the
+// plugin is in the Jsep field of the returned joined event.
+func (p *VideoroomPublisher) JoinAndConfigure(join *VideoroomPublisherJoin, configure *VideoroomPublisherConfigure, offer map[string]interface{}) (*VideoroomJoinedEvent, error) {
+	return p.JoinAndConfigureContext(context.Background(), join, configure, offer)
+}
+
+// JoinAndConfigureContext is like JoinAndConfigure, but gives up waiting for
+// the response when ctx is done.
+func (p *VideoroomPublisher) JoinAndConfigureContext(ctx context.Context, join *VideoroomPublisherJoin, configure *VideoroomPublisherConfigure, offer map[string]interface{}) (*VideoroomJoinedEvent, error) {
+	return p.join(ctx, "joinandconfigure", join, configure, offer)
+}
+
+func (p *VideoroomPublisher) join(ctx context.Context, request string, join *VideoroomPublisherJoin, configure *VideoroomPublisherConfigure, jsep map[string]interface{}) (*VideoroomJoinedEvent, error) {
+	body, err := janus.StructToMap(join)
+	if err != nil {
+		return nil, err
+	}
+	if configure != nil {
+		settings, err := janus.StructToMap(configure)
+		if err != nil {
+			return nil, err
+		}
+		mergeMap(body, settings)
+	}
+	body["ptype"] = "publisher"
+
+	event, err := videoroomMessage(ctx, p.Handle, request, body, jsep)
+	if err != nil {
+		return nil, err
+	}
+	joined, ok := event.(*VideoroomJoinedEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to videoroom %s: %v", request, event)
+	}
+
+	p.Room = joined.Room
+	p.ID = joined.ID
+	p.PrivateID = joined.PrivateID
+	return joined, nil
+}
+
+// Publish starts publishing media in the joined room. offer should be the
+// SDP offer of the publisher, the SDP answer of the plugin is in the Jsep
+// field of the returned configured event.
+func (p *VideoroomPublisher) Publish(publish *VideoroomPublisherConfigure, offer map[string]interface{}) (*VideoroomConfiguredEvent, error) {
+	return p.PublishContext(context.Background(), publish, offer)
+}
+
+// PublishContext is like Publish, but gives up waiting for the response when
+// ctx is done.
+func (p *VideoroomPublisher) PublishContext(ctx context.Context, publish *VideoroomPublisherConfigure, offer map[string]interface{}) (*VideoroomConfiguredEvent, error) {
+	return p.configure(ctx, "publish", publish, offer)
+}
+
+// Configure changes the settings of the publisher. jsep can carry an SDP
+// offer to renegotiate the PeerConnection, or be nil.
+func (p *VideoroomPublisher) Configure(configure *VideoroomPublisherConfigure, jsep map[string]interface{}) (*VideoroomConfiguredEvent, error) {
+	return p.ConfigureContext(context.Background(), configure, jsep)
+}
+
+// ConfigureContext is like Configure, but gives up waiting for the response
+// when ctx is done.
+func (p *VideoroomPublisher) ConfigureContext(ctx context.Context, configure *VideoroomPublisherConfigure, jsep map[string]interface{}) (*VideoroomConfiguredEvent, error) {
+	return p.configure(ctx, "configure", configure, jsep)
+}
+
+func (p *VideoroomPublisher) configure(ctx context.Context, request string, configure *VideoroomPublisherConfigure, jsep map[string]interface{}) (*VideoroomConfiguredEvent, error) {
+	if configure == nil {
+		configure = &VideoroomPublisherConfigure{}
+	}
+
+	event, err := videoroomMessage(ctx, p.Handle, request, configure, jsep)
+	if err != nil {
+		return nil, err
+	}
+	configured, ok := event.(*VideoroomConfiguredEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to videoroom %s: %v", request, event)
+	}
+	return configured, nil
+}
+
+// Unpublish stops publishing, while staying in the room.
+// On success, error will be nil.
+func (p *VideoroomPublisher) Unpublish() error {
+	return p.UnpublishContext(context.Background())
+}
+
+// UnpublishContext is like Unpublish, but gives up waiting for the response
+// when ctx is done.
+func (p *VideoroomPublisher) UnpublishContext(ctx context.Context) error {
+	event, err := videoroomMessage(ctx, p.Handle, "unpublish", nil, nil)
+	if err != nil {
+		return err
+	}
+	if _, ok := event.(*VideoroomUnpublishedEvent); !ok {
+		return fmt.Errorf("unexpected response to videoroom unpublish: %v", event)
+	}
+	return nil
+}
+
+// Leave leaves the room, which also stops publishing.
+// On success, error will be nil.
+func (p *VideoroomPublisher) Leave() error {
+	return p.LeaveContext(context.Background())
+}
+
+// LeaveContext is like Leave, but gives up waiting for the response when ctx
+// is done.
+func (p *VideoroomPublisher) LeaveContext(ctx context.Context) error {
+	event, err := videoroomMessage(ctx, p.Handle, "leave", nil, nil)
+	if err != nil {
+		return err
+	}
+	if _, ok := event.(*VideoroomLeavingEvent); !ok {
+		return fmt.Errorf("unexpected response to videoroom leave: %v", event)
+	}
+
+	p.Room = 0
+	p.ID = 0
+	p.PrivateID = 0
+	return nil
+}
@@ -0,0 +1,173 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestVideoroomPublisher(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	answer := map[string]interface{}{"type": "answer", "sdp": "v=0"}
+	server.HandlePlugin("janus.plugin.videoroom", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		switch msg.Body["request"] {
+		case "join", "joinandconfigure":
+			if msg.Body["ptype"] != "publisher" {
+				t.Errorf("unexpected ptype %v", msg.Body["ptype"])
+			}
+			if msg.Body["room"] != 1234.0 {
+				return event(map[string]interface{}{"videoroom": "event", "error_code": 426, "error": "No such room"}, nil)
+			}
+			var jsep map[string]interface{}
+			if msg.Jsep != nil {
+				jsep = answer
+			}
+			return event(map[string]interface{}{
+				"videoroom":   "joined",
+				"room":        1234,
+				"description": "Demo Room",
+				"id":          42,
+				"private_id":  4242,
+				"publishers": []interface{}{
+					map[string]interface{}{"id": 7, "display": "alice", "streams": []interface{}{
+						map[string]interface{}{"type": "audio", "mindex": 0, "mid": "0", "codec": "opus"},
+						map[string]interface{}{"type": "video", "mindex": 1, "mid": "1", "codec": "vp8", "description": "webcam", "simulcast": true},
+					}},
+				},
+			}, jsep)
+		case "publish", "configure":
+			if msg.Body["bitrate"] != 128000.0 {
+				t.Errorf("unexpected bitrate %v", msg.Body["bitrate"])
+			}
+			if _, ok := msg.Body["audio"]; ok {
+				t.Errorf("unexpected deprecated audio setting %v", msg.Body)
+			}
+			return event(map[string]interface{}{
+				"videoroom":  "event",
+				"room":       1234,
+				"configured": "ok",
+				"streams":    []interface{}{map[string]interface{}{"type": "video", "mindex": 0, "mid": "0", "codec": "vp8", "description": "screen"}},
+			}, answer)
+		case "unpublish":
+			return event(map[string]interface{}{"videoroom": "event", "room": 1234, "unpublished": "ok"}, nil)
+		case "leave":
+			return event(map[string]interface{}{"videoroom": "event", "room": 1234, "leaving": "ok"}, nil)
+		}
+		return nil
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.videoroom")
+	defer gateway.Close()
+
+	publisher := NewVideoroomPublisher(handle)
+	_, err := publisher.Join(&VideoroomPublisherJoin{Room: 1, Display: "bob"})
+	if _, ok := err.(*VideoroomErrorResponse); !ok {
+		t.Fatalf("expected a VideoroomErrorResponse, got %v", err)
+	}
+
+	joined, err := publisher.Join(&VideoroomPublisherJoin{Room: 1234, Display: "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if publisher.ID != 42 || publisher.PrivateID != 4242 || publisher.Room != 1234 {
+		t.Errorf("unexpected publisher state %+v", publisher)
+	}
+	if len(joined.Publishers) != 1 || joined.Publishers[0].Display != "alice" {
+		t.Errorf("unexpected publishers %v", joined.Publishers)
+	}
+	if streams := joined.Publishers[0].Streams; len(streams) != 2 || streams[1].Mid != "1" || streams[1].Codec != "vp8" || !streams[1].Simulcast {
+		t.Errorf("unexpected publisher streams %+v", streams)
+	}
+
+	offer := map[string]interface{}{"type": "offer", "sdp": "v=0"}
+	configured, err := publisher.Publish(&VideoroomPublisherConfigure{
+		Bitrate:      128000,
+		Descriptions: []*VideoroomStreamDescription{{Mid: "0", Description: "screen"}},
+	}, offer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configured.Jsep["type"] != "answer" {
+		t.Errorf("expected an answer, got %v", configured.Jsep)
+	}
+	if len(configured.Streams) != 1 || configured.Streams[0].Description != "screen" {
+		t.Errorf("unexpected configured streams %+v", configured.Streams)
+	}
+
+	send := false
+	if _, err := publisher.Configure(&VideoroomPublisherConfigure{
+		Bitrate: 128000,
+		Streams: []*VideoroomPublisherStreamConfigure{{Mid: "0", Send: &send}},
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := publisher.Unpublish(); err != nil {
+		t.Error(err)
+	}
+	if err := publisher.Leave(); err != nil {
+		t.Error(err)
+	}
+
+	joined, err = publisher.JoinAndConfigure(&VideoroomPublisherJoin{Room: 1234}, &VideoroomPublisherConfigure{Bitrate: 128000}, offer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if joined.Jsep["type"] != "answer" {
+		t.Errorf("expected an answer, got %v", joined.Jsep)
+	}
+}
+
+func TestParseVideoroomEvent(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.videoroom")
+	defer gateway.Close()
+
+	push := func(data map[string]interface{}) interface{} {
+		if err := server.PushEvent(server.Sessions()[0], handle.ID, data, nil); err != nil {
+			t.Fatal(err)
+		}
+		event, err := ParseVideoroomEvent(nextEvent(t, handle))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+
+	publishers, ok := push(map[string]interface{}{
+		"videoroom":  "event",
+		"room":       1234,
+		"publishers": []interface{}{map[string]interface{}{"id": 8, "display": "carol"}},
+	}).(*VideoroomPublishersEvent)
+	if !ok || len(publishers.Publishers) != 1 || publishers.Publishers[0].ID != 8 {
+		t.Errorf("unexpected publishers event %+v", publishers)
+	}
+
+	unpublished, ok := push(map[string]interface{}{"videoroom": "event", "room": 1234, "unpublished": 8}).(*VideoroomUnpublishedEvent)
+	if !ok || unpublished.ID != 8 {
+		t.Errorf("unexpected unpublished event %+v", unpublished)
+	}
+
+	leaving, ok := push(map[string]interface{}{"videoroom": "event", "room": 1234, "leaving": "ok", "reason": "kicked"}).(*VideoroomLeavingEvent)
+	if !ok || leaving.ID != 0 || leaving.Reason != "kicked" {
+		t.Errorf("unexpected leaving event %+v", leaving)
+	}
+
+	talking, ok := push(map[string]interface{}{"videoroom": "talking", "room": 1234, "id": 8, "audio-level-dBov-avg": -30.5}).(*VideoroomTalkingEvent)
+	if !ok || !talking.Talking || talking.ID != 8 || talking.AudioLevel != -30.5 {
+		t.Errorf("unexpected talking event %+v", talking)
+	}
+
+	stopped, ok := push(map[string]interface{}{"videoroom": "stopped-talking", "room": 1234, "id": 8}).(*VideoroomTalkingEvent)
+	if !ok || stopped.Talking {
+		t.Errorf("unexpected stopped-talking event %+v", stopped)
+	}
+
+	if _, ok := push(map[string]interface{}{"videoroom": "destroyed", "room": 1234}).(map[string]interface{}); !ok {
+		t.Error("expected unknown events to be returned raw")
+	}
+}
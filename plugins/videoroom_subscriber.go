@@ -0,0 +1,280 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+// VideoroomSubscriber is a participant of a videoroom in the subscriber
+// role, receiving the streams of one or more publishers through a single
+// PeerConnection, on top of a handle attached to janus.plugin.videoroom.
+type VideoroomSubscriber struct {
+	Handle *janus.Handle
+
+	// Room is set once the subscriber joined a room.
+	Room int
+
+	// Streams are the streams of the PeerConnection, as of the last
+	// attached or updated event answering a request of the subscriber.
+	Streams []*VideoroomSubscriberStream
+}
+
+func NewVideoroomSubscriber(handle *janus.Handle) *VideoroomSubscriber {
+	return &VideoroomSubscriber{Handle: handle}
+}
+
+// VideoroomSubscription selects streams of a publisher. Mid selects a single
+// stream of the publisher Feed, all of them if empty. SubMid selects a stream
+// of the subscriber, for switch requests and unsubscribing.
+type VideoroomSubscription struct {
+	Feed   uint64 `json:"feed,omitempty"`
+	Mid    string `json:"mid,omitempty"`
+	SubMid string `json:"sub_mid,omitempty"`
+}
+
+type VideoroomSubscriberJoin struct {
+	Room       int                      `json:"room"`
+	Streams    []*VideoroomSubscription `json:"streams"`
+	PrivateID  uint64                   `json:"private_id,omitempty"`
+	UseMsid    bool                     `json:"use_msid,omitempty"`
+	AutoUpdate *bool                    `json:"autoupdate,omitempty"`
+	Pin        string                   `json:"pin,omitempty"`
+}
+
+// VideoroomStreamConfigure changes the settings of the subscriber stream
+// Mid. Substream and Temporal select the simulcast layers, SpatialLayer and
+// TemporalLayer the SVC layers. nil fields are left unchanged.
+type VideoroomStreamConfigure struct {
+	Mid           string `json:"mid"`
+	Send          *bool  `json:"send,omitempty"`
+	Substream     *int   `json:"substream,omitempty"`
+	Temporal      *int   `json:"temporal,omitempty"`
+	Fallback      int    `json:"fallback,omitempty"`
+	SpatialLayer  *int   `json:"spatial_layer,omitempty"`
+	TemporalLayer *int   `json:"temporal_layer,omitempty"`
+}
+
+type VideoroomSubscriberStream struct {
+	Mindex      int    `json:"mindex"`
+	Mid         string `json:"mid"`
+	Type        string `json:"type"`
+	Active      bool   `json:"active"`
+	FeedID      uint64 `json:"feed_id"`
+	FeedMid     string `json:"feed_mid"`
+	FeedDisplay string `json:"feed_display"`
+	Send        bool   `json:"send"`
+	Codec       string `json:"codec"`
+	Ready       bool   `json:"ready"`
+}
+
+// VideoroomAttachedEvent answers the join of a subscriber, with the SDP offer
+// of the plugin in Jsep.
+type VideoroomAttachedEvent struct {
+	VideoroomResponse
+	Room    int                          `json:"room"`
+	Streams []*VideoroomSubscriberStream `json:"streams"`
+	Jsep    map[string]interface{}       `json:"-"`
+}
+
+// VideoroomUpdatedEvent notifies that the streams of a subscriber changed,
+// with a new SDP offer of the plugin in Jsep.
+type VideoroomUpdatedEvent struct {
+	VideoroomResponse
+	Room    int                          `json:"room"`
+	Streams []*VideoroomSubscriberStream `json:"streams"`
+	Jsep    map[string]interface{}       `json:"-"`
+}
+
+type VideoroomStartedEvent struct {
+	VideoroomResponse
+	Room    int    `json:"room"`
+	Started string `json:"started"`
+}
+
+type VideoroomPausedEvent struct {
+	VideoroomResponse
+	Room   int    `json:"room"`
+	Paused string `json:"paused"`
+}
+
+type VideoroomSwitchedEvent struct {
+	VideoroomResponse
+	Room     int                          `json:"room"`
+	Switched string                       `json:"switched"`
+	Changes  int                          `json:"changes"`
+	Streams  []*VideoroomSubscriberStream `json:"streams"`
+}
+
+type VideoroomLeftEvent struct {
+	VideoroomResponse
+	Room int    `json:"room"`
+	Left string `json:"left"`
+}
+
+// Join subscribes to the given streams of one or more publishers.
+// On success, the attached event carrying the SDP offer of the plugin will
+// be returned and error will be nil. The answer is sent with Start.
+func (s *VideoroomSubscriber) Join(join *VideoroomSubscriberJoin) (*VideoroomAttachedEvent, error) {
+	return s.JoinContext(context.Background(), join)
+}
+
+// JoinContext is like Join, but gives up waiting for the response when ctx
+// is done.
+func (s *VideoroomSubscriber) JoinContext(ctx context.Context, join *VideoroomSubscriberJoin) (*VideoroomAttachedEvent, error) {
+	body, err := janus.StructToMap(join)
+	if err != nil {
+		return nil, err
+	}
+	body["ptype"] = "subscriber"
+
+	event, err := videoroomMessage(ctx, s.Handle, "join", body, nil)
+	if err != nil {
+		return nil, err
+	}
+	attached, ok := event.(*VideoroomAttachedEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to videoroom join: %v", event)
+	}
+
+	s.Room = attached.Room
+	s.Streams = attached.Streams
+	return attached, nil
+}
+
+// Start completes the negotiation of the PeerConnection with the SDP answer
+// of the subscriber, after which media starts flowing.
+// On success, error will be nil.
+func (s *VideoroomSubscriber) Start(answer map[string]interface{}) error {
+	return s.StartContext(context.Background(), answer)
+}
+
+// StartContext is like Start, but gives up waiting for the response when ctx
+// is done.
+func (s *VideoroomSubscriber) StartContext(ctx context.Context, answer map[string]interface{}) error {
+	event, err := videoroomMessage(ctx, s.Handle, "start", nil, answer)
+	if err != nil {
+		return err
+	}
+	if _, ok := event.(*VideoroomStartedEvent); !ok {
+		return fmt.Errorf("unexpected response to videoroom start: %v", event)
+	}
+	return nil
+}
+
+// Pause stops the delivery of media until Start is sent again.
+// On success, error will be nil.
+func (s *VideoroomSubscriber) Pause() error {
+	return s.PauseContext(context.Background())
+}
+
+// PauseContext is like Pause, but gives up waiting for the response when ctx
+// is done.
+func (s *VideoroomSubscriber) PauseContext(ctx context.Context) error {
+	event, err := videoroomMessage(ctx, s.Handle, "pause", nil, nil)
+	if err != nil {
+		return err
+	}
+	if _, ok := event.(*VideoroomPausedEvent); !ok {
+		return fmt.Errorf("unexpected response to videoroom pause: %v", event)
+	}
+	return nil
+}
+
+// Switch changes the publisher streams feeding existing subscriber streams,
+// without renegotiating. Each entry maps the subscriber stream SubMid to the
+// stream Mid of the publisher Feed.
+func (s *VideoroomSubscriber) Switch(streams []*VideoroomSubscription) (*VideoroomSwitchedEvent, error) {
+	return s.SwitchContext(context.Background(), streams)
+}
+
+// SwitchContext is like Switch, but gives up waiting for the response when
+// ctx is done.
+func (s *VideoroomSubscriber) SwitchContext(ctx context.Context, streams []*VideoroomSubscription) (*VideoroomSwitchedEvent, error) {
+	body := map[string]interface{}{"streams": streams}
+	event, err := videoroomMessage(ctx, s.Handle, "switch", body, nil)
+	if err != nil {
+		return nil, err
+	}
+	switched, ok := event.(*VideoroomSwitchedEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to videoroom switch: %v", event)
+	}
+	return switched, nil
+}
+
+// Update adds and removes streams of the subscriber in a single
+// renegotiation. On success, the updated event carrying the new SDP offer of
+// the plugin will be returned and error will be nil. The answer is sent with
+// Start.
+func (s *VideoroomSubscriber) Update(subscribe, unsubscribe []*VideoroomSubscription) (*VideoroomUpdatedEvent, error) {
+	return s.UpdateContext(context.Background(), subscribe, unsubscribe)
+}
+
+// UpdateContext is like Update, but gives up waiting for the response when
+// ctx is done.
+func (s *VideoroomSubscriber) UpdateContext(ctx context.Context, subscribe, unsubscribe []*VideoroomSubscription) (*VideoroomUpdatedEvent, error) {
+	body := map[string]interface{}{}
+	if len(subscribe) > 0 {
+		body["subscribe"] = subscribe
+	}
+	if len(unsubscribe) > 0 {
+		body["unsubscribe"] = unsubscribe
+	}
+
+	event, err := videoroomMessage(ctx, s.Handle, "update", body, nil)
+	if err != nil {
+		return nil, err
+	}
+	updated, ok := event.(*VideoroomUpdatedEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to videoroom update: %v", event)
+	}
+
+	s.Streams = updated.Streams
+	return updated, nil
+}
+
+// Configure changes the settings of subscriber streams, e.g. to select the
+// simulcast substream and temporal layer, or the SVC layers, to receive.
+// On success, error will be nil.
+func (s *VideoroomSubscriber) Configure(streams []*VideoroomStreamConfigure) error {
+	return s.ConfigureContext(context.Background(), streams)
+}
+
+// ConfigureContext is like Configure, but gives up waiting for the response
+// when ctx is done.
+func (s *VideoroomSubscriber) ConfigureContext(ctx context.Context, streams []*VideoroomStreamConfigure) error {
+	body := map[string]interface{}{"streams": streams}
+	event, err := videoroomMessage(ctx, s.Handle, "configure", body, nil)
+	if err != nil {
+		return err
+	}
+	if _, ok := event.(*VideoroomConfiguredEvent); !ok {
+		return fmt.Errorf("unexpected response to videoroom configure: %v", event)
+	}
+	return nil
+}
+
+// Leave stops the subscription, tearing the PeerConnection down.
+// On success, error will be nil.
+func (s *VideoroomSubscriber) Leave() error {
+	return s.LeaveContext(context.Background())
+}
+
+// LeaveContext is like Leave, but gives up waiting for the response when ctx
+// is done.
+func (s *VideoroomSubscriber) LeaveContext(ctx context.Context) error {
+	event, err := videoroomMessage(ctx, s.Handle, "leave", nil, nil)
+	if err != nil {
+		return err
+	}
+	if _, ok := event.(*VideoroomLeftEvent); !ok {
+		return fmt.Errorf("unexpected response to videoroom leave: %v", event)
+	}
+
+	s.Room = 0
+	s.Streams = nil
+	return nil
+}
@@ -0,0 +1,127 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestVideoroomSubscriber(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	offer := map[string]interface{}{"type": "offer", "sdp": "v=0"}
+	stream := func(mid string, feed int) map[string]interface{} {
+		return map[string]interface{}{"mindex": 0, "mid": mid, "type": "video", "active": true, "feed_id": feed, "feed_mid": "1"}
+	}
+	server.HandlePlugin("janus.plugin.videoroom", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		switch msg.Body["request"] {
+		case "join":
+			if msg.Body["ptype"] != "subscriber" {
+				t.Errorf("unexpected ptype %v", msg.Body["ptype"])
+			}
+			streams, _ := msg.Body["streams"].([]interface{})
+			if len(streams) != 2 {
+				t.Errorf("expected 2 streams, got %v", msg.Body["streams"])
+			}
+			return event(map[string]interface{}{
+				"videoroom": "attached",
+				"room":      1234,
+				"streams":   []interface{}{stream("0", 7), stream("1", 8)},
+			}, offer)
+		case "start":
+			if msg.Jsep["type"] != "answer" {
+				t.Errorf("expected an answer, got %v", msg.Jsep)
+			}
+			return event(map[string]interface{}{"videoroom": "event", "room": 1234, "started": "ok"}, nil)
+		case "pause":
+			return event(map[string]interface{}{"videoroom": "event", "room": 1234, "paused": "ok"}, nil)
+		case "switch":
+			return event(map[string]interface{}{
+				"videoroom": "event",
+				"room":      1234,
+				"switched":  "ok",
+				"changes":   1,
+				"streams":   []interface{}{stream("0", 9), stream("1", 8)},
+			}, nil)
+		case "update":
+			if _, ok := msg.Body["subscribe"]; !ok {
+				t.Error("expected a subscribe list")
+			}
+			if _, ok := msg.Body["unsubscribe"]; !ok {
+				t.Error("expected an unsubscribe list")
+			}
+			return event(map[string]interface{}{
+				"videoroom": "updated",
+				"room":      1234,
+				"streams":   []interface{}{stream("0", 9)},
+			}, offer)
+		case "configure":
+			streams, _ := msg.Body["streams"].([]interface{})
+			if len(streams) != 1 || streams[0].(map[string]interface{})["substream"] != 0.0 {
+				t.Errorf("unexpected streams %v", msg.Body["streams"])
+			}
+			return event(map[string]interface{}{"videoroom": "event", "room": 1234, "configured": "ok"}, nil)
+		case "leave":
+			return event(map[string]interface{}{"videoroom": "event", "room": 1234, "left": "ok"}, nil)
+		}
+		return nil
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.videoroom")
+	defer gateway.Close()
+
+	subscriber := NewVideoroomSubscriber(handle)
+	attached, err := subscriber.Join(&VideoroomSubscriberJoin{
+		Room:    1234,
+		Streams: []*VideoroomSubscription{{Feed: 7}, {Feed: 8, Mid: "1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attached.Jsep["type"] != "offer" {
+		t.Errorf("expected an offer, got %v", attached.Jsep)
+	}
+	if len(subscriber.Streams) != 2 || subscriber.Streams[1].FeedID != 8 {
+		t.Errorf("unexpected streams %v", subscriber.Streams)
+	}
+
+	answer := map[string]interface{}{"type": "answer", "sdp": "v=0"}
+	if err := subscriber.Start(answer); err != nil {
+		t.Error(err)
+	}
+	if err := subscriber.Pause(); err != nil {
+		t.Error(err)
+	}
+
+	switched, err := subscriber.Switch([]*VideoroomSubscription{{Feed: 9, Mid: "1", SubMid: "0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if switched.Changes != 1 || switched.Streams[0].FeedID != 9 {
+		t.Errorf("unexpected switched event %+v", switched)
+	}
+
+	updated, err := subscriber.Update(
+		[]*VideoroomSubscription{{Feed: 9}},
+		[]*VideoroomSubscription{{Feed: 7}, {SubMid: "1"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Jsep["type"] != "offer" || len(subscriber.Streams) != 1 {
+		t.Errorf("unexpected updated event %+v", updated)
+	}
+
+	substream := 0
+	if err := subscriber.Configure([]*VideoroomStreamConfigure{{Mid: "0", Substream: &substream}}); err != nil {
+		t.Error(err)
+	}
+
+	if err := subscriber.Leave(); err != nil {
+		t.Error(err)
+	}
+	if subscriber.Room != 0 || subscriber.Streams != nil {
+		t.Errorf("unexpected subscriber state after leave %+v", subscriber)
+	}
+}
@@ -0,0 +1,108 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tatsujin1/janus-go"
+)
+
+type VoicemailResponse struct {
+	Voicemail string `json:"voicemail"`
+}
+
+type VoicemailErrorResponse struct {
+	VoicemailResponse
+	PluginError
+}
+
+func (err *VoicemailErrorResponse) Error() string {
+	return err.PluginError.Error()
+}
+
+// VoicemailClient records an audio message, on top of a handle attached to
+// janus.plugin.voicemail.
+type VoicemailClient struct {
+	Handle *janus.Handle
+}
+
+func NewVoicemailClient(handle *janus.Handle) *VoicemailClient {
+	return &VoicemailClient{Handle: handle}
+}
+
+// VoicemailEvent notifies a change of the status of the recording:
+// "starting" (with the SDP answer of the plugin in Jsep), "stopped", or
+// "done" with the URI of the recording, relative to the base configured in
+// the plugin, in Recording.
+type VoicemailEvent struct {
+	VoicemailResponse
+	Status    string                 `json:"status"`
+	ID        uint64                 `json:"id"`
+	Recording string                 `json:"recording"`
+	Jsep      map[string]interface{} `json:"-"`
+}
+
+// ParseVoicemailEvent decodes the plugin data of an event received from the
+// voicemail plugin to a *VoicemailEvent, or to a *VoicemailErrorResponse.
+// Unknown events are returned as the raw plugin data.
+func ParseVoicemailEvent(event *janus.EventMsg) (interface{}, error) {
+	data := event.Plugindata.Data
+
+	var v interface{}
+	if _, ok := data["error"]; ok {
+		v = &VoicemailErrorResponse{}
+	} else if _, ok := data["status"]; ok {
+		v = &VoicemailEvent{Jsep: event.Jsep}
+	}
+	if v == nil {
+		return data, nil
+	}
+
+	if err := decodePluginData(data, v); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal voicemail event : %w", err)
+	}
+	return v, nil
+}
+
+// voicemailMessage sends a request to the voicemail plugin through handle,
+// and parses the event answering it.
+func voicemailMessage(ctx context.Context, handle *janus.Handle, request string, jsep map[string]interface{}) (*VoicemailEvent, error) {
+	event, err := pluginMessage(ctx, handle, request, nil, jsep, &VoicemailErrorResponse{}, ParseVoicemailEvent)
+	if err != nil {
+		return nil, err
+	}
+	status, ok := event.(*VoicemailEvent)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to voicemail %s: %v", request, event)
+	}
+	return status, nil
+}
+
+// Record starts recording the audio of the PeerConnection negotiated with
+// the SDP offer of the user. The recording stops by itself after some
+// seconds, or with Stop, and its URI is then notified by a done
+// VoicemailEvent.
+// On success, the starting event carrying the SDP answer of the plugin will
+// be returned and error will be nil.
+func (c *VoicemailClient) Record(offer map[string]interface{}) (*VoicemailEvent, error) {
+	return c.RecordContext(context.Background(), offer)
+}
+
+// RecordContext is like Record, but gives up waiting for the response when
+// ctx is done.
+func (c *VoicemailClient) RecordContext(ctx context.Context, offer map[string]interface{}) (*VoicemailEvent, error) {
+	return voicemailMessage(ctx, c.Handle, "record", offer)
+}
+
+// Stop stops the recording before its end.
+// On success, the event answering the request will be returned and error
+// will be nil.
+func (c *VoicemailClient) Stop() (*VoicemailEvent, error) {
+	return c.StopContext(context.Background())
+}
+
+// StopContext is like Stop, but gives up waiting for the response when ctx
+// is done.
+func (c *VoicemailClient) StopContext(ctx context.Context) (*VoicemailEvent, error) {
+	return voicemailMessage(ctx, c.Handle, "stop", nil)
+}
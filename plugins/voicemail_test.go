@@ -0,0 +1,49 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/tatsujin1/janus-go/janustest"
+)
+
+func TestVoicemailClient(t *testing.T) {
+	server := janustest.NewServer()
+	defer server.Close()
+
+	server.HandlePlugin("janus.plugin.voicemail", func(msg *janustest.PluginMessage) *janustest.PluginResponse {
+		switch msg.Body["request"] {
+		case "record":
+			return event(map[string]interface{}{"voicemail": "event", "status": "starting", "id": 42}, map[string]interface{}{"type": "answer", "sdp": "v=0"})
+		case "stop":
+			return event(map[string]interface{}{"voicemail": "event", "status": "stopped"}, nil)
+		}
+		return nil
+	})
+
+	gateway, handle := newTestHandle(t, server, "janus.plugin.voicemail")
+	defer gateway.Close()
+
+	client := NewVoicemailClient(handle)
+	starting, err := client.Record(map[string]interface{}{"type": "offer", "sdp": "v=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if starting.Status != "starting" || starting.ID != 42 || starting.Jsep["type"] != "answer" {
+		t.Errorf("unexpected record response %+v", starting)
+	}
+
+	if err := server.PushEvent(server.Sessions()[0], handle.ID, map[string]interface{}{"voicemail": "event", "status": "done", "recording": "/voicemail/rec-42.opus"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	event, err := ParseVoicemailEvent(nextEvent(t, handle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done, ok := event.(*VoicemailEvent); !ok || done.Status != "done" || done.Recording != "/voicemail/rec-42.opus" {
+		t.Errorf("unexpected done event %+v", event)
+	}
+
+	if stopped, err := client.Stop(); err != nil || stopped.Status != "stopped" {
+		t.Errorf("unexpected stop response %+v, %v", stopped, err)
+	}
+}
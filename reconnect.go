@@ -0,0 +1,166 @@
+package janus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Timeout of the claims sent after reconnecting, unless the Gateway has a
+// RequestTimeout.
+const reclaimTimeout = 10 * time.Second
+
+// ReconnectPolicy controls how a Gateway re-establishes a lost connection.
+// The requests waiting for a response when the connection is lost fail with
+// ErrConnectionLost. Once reconnected, every session in Gateway.Sessions is
+// claimed on the new connection, and the outcome of each claim is reported
+// on the channel returned by Gateway.GetReclaimChan().
+type ReconnectPolicy struct {
+	// MaxAttempts is the number of dial attempts before giving up, 0 retries
+	// forever.
+	MaxAttempts int
+
+	// InitialDelay is the delay before the first dial attempt, 1s if unset.
+	InitialDelay time.Duration
+
+	// MaxDelay caps the delay between dial attempts, 30s if unset.
+	MaxDelay time.Duration
+
+	// Multiplier is applied to the delay after each failed attempt, 2 if unset.
+	Multiplier float64
+}
+
+func (policy *ReconnectPolicy) initialDelay() time.Duration {
+	if policy.InitialDelay > 0 {
+		return policy.InitialDelay
+	}
+	return time.Second
+}
+
+func (policy *ReconnectPolicy) nextDelay(delay time.Duration) time.Duration {
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	maxDelay := policy.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+
+	delay = time.Duration(float64(delay) * multiplier)
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// ReclaimResult is the outcome of claiming a session after reconnecting.
+// Err is nil if the session survived, otherwise the session has been removed
+// from Gateway.Sessions, and the result is also delivered on its Events.
+type ReclaimResult struct {
+	Session *Session
+	Err     error
+}
+
+// GetReclaimChan returns a channel through which the caller is notified of
+// the outcome of claiming each session after a reconnect.
+func (gateway *Gateway) GetReclaimChan() chan *ReclaimResult {
+	return gateway.reclaims
+}
+
+// reconnect dials the gateway again according to the reconnect policy,
+// returning false if reconnection is disabled or all attempts failed.
+func (gateway *Gateway) reconnect() bool {
+	policy := gateway.Reconnect
+	if policy == nil || gateway.Dial == nil {
+		return false
+	}
+
+	delay := policy.initialDelay()
+	for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		select {
+		case <-time.After(delay):
+		case <-gateway.closed:
+			return false
+		}
+
+		transport, err := gateway.Dial()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "janus reconnect attempt %d: %s\n", attempt, err)
+			delay = policy.nextDelay(delay)
+			continue
+		}
+
+		gateway.transportMu.Lock()
+		old := gateway.transport
+		gateway.transport = transport
+		gateway.transportGen++
+		gen := gateway.transportGen
+		gateway.transportMu.Unlock()
+		old.Close()
+
+		// The responses to the requests sent on the lost connection will
+		// never arrive, while those sent on the new one may.
+		gateway.transactions.failBefore(gen, ErrConnectionLost)
+
+		go gateway.reclaim()
+		return true
+	}
+
+	// No response will arrive anymore.
+	gateway.transportMu.RLock()
+	gen := gateway.transportGen
+	gateway.transportMu.RUnlock()
+	gateway.transactions.failBefore(gen+1, ErrConnectionLost)
+
+	err := fmt.Errorf("janus: giving up reconnecting after %d attempts", policy.MaxAttempts)
+	select {
+	case gateway.errors <- err:
+	default:
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+	}
+	return false
+}
+
+func (gateway *Gateway) reclaim() {
+	gateway.Lock()
+	sessions := make([]*Session, 0, len(gateway.Sessions))
+	for _, session := range gateway.Sessions {
+		sessions = append(sessions, session)
+	}
+	gateway.Unlock()
+
+	for _, session := range sessions {
+		err := gateway.reclaimSession(session)
+		result := &ReclaimResult{Session: session, Err: err}
+		if err != nil {
+			gateway.Lock()
+			delete(gateway.Sessions, session.ID)
+			gateway.Unlock()
+			session.stopKeepAlive()
+			session.events.push(result)
+		}
+
+		select {
+		case gateway.reclaims <- result:
+		default:
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "janus reclaim of session %d: %s\n", session.ID, err)
+			}
+		}
+	}
+}
+
+// reclaimSession claims a session on the new connection, giving up after
+// RequestTimeout or reclaimTimeout.
+func (gateway *Gateway) reclaimSession(session *Session) error {
+	ctx := context.Background()
+	if gateway.RequestTimeout <= 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, reclaimTimeout)
+		defer cancel()
+	}
+	_, err := session.ClaimContext(ctx)
+	return err
+}
@@ -0,0 +1,124 @@
+package janus
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestGateway_Reconnect(t *testing.T) {
+	claims := make(chan uint64, 2)
+	respond := func(req map[string]interface{}) []string {
+		switch req["janus"] {
+		case "create":
+			return []string{fmt.Sprintf(`{"janus":"success","transaction":"%s","data":{"id":1}}`, req["transaction"])}
+		case "claim":
+			id := uint64(req["session_id"].(float64))
+			claims <- id
+			if id == 1 {
+				return []string{fmt.Sprintf(`{"janus":"success","session_id":1,"transaction":"%s"}`, req["transaction"])}
+			}
+			return []string{fmt.Sprintf(`{"janus":"error","session_id":%d,"transaction":"%s","error":{"code":458,"reason":"No such session"}}`, id, req["transaction"])}
+		}
+		t.Errorf("unexpected request %v", req["janus"])
+		return nil
+	}
+
+	first := newMemTransport(respond)
+	gateway := NewGateway(first)
+	defer gateway.Close()
+
+	dials := 0
+	gateway.Reconnect = &ReconnectPolicy{InitialDelay: time.Millisecond, MaxAttempts: 3}
+	gateway.Dial = func() (Transport, error) {
+		dials++
+		if dials == 1 {
+			return nil, fmt.Errorf("connection refused")
+		}
+		return newMemTransport(respond), nil
+	}
+
+	session, err := gateway.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a session which expired while disconnected
+	gone := &Session{ID: 2, Handles: make(map[uint64]*Handle), gateway: gateway}
+	gone.events = gateway.newEventQueue()
+	gone.Events = gone.events.ch
+	gateway.Lock()
+	gateway.Sessions[gone.ID] = gone
+	gateway.Unlock()
+
+	first.Close()
+
+	results := map[uint64]error{}
+	for len(results) < 2 {
+		select {
+		case res := <-gateway.GetReclaimChan():
+			results[res.Session.ID] = res.Err
+		case <-time.After(time.Second):
+			t.Fatal("reclaim results not reported")
+		}
+	}
+
+	if results[session.ID] != nil {
+		t.Errorf("expected session %d to be reclaimed, got %s", session.ID, results[session.ID])
+	}
+	if results[gone.ID] == nil {
+		t.Errorf("expected reclaim of session %d to fail", gone.ID)
+	}
+
+	gateway.Lock()
+	_, ok := gateway.Sessions[gone.ID]
+	gateway.Unlock()
+	if ok {
+		t.Errorf("session %d should have been removed", gone.ID)
+	}
+
+	select {
+	case msg := <-gone.Events:
+		if res, ok := msg.(*ReclaimResult); !ok || res.Err == nil {
+			t.Errorf("expected a failed reclaim on Events, got %#v", msg)
+		}
+	default:
+		t.Errorf("failed reclaim not delivered on Events")
+	}
+}
+
+func TestGateway_ReconnectFailsPending(t *testing.T) {
+	respond := func(req map[string]interface{}) []string {
+		// never answer, as if the connection was lost in the meantime
+		return nil
+	}
+
+	first := newMemTransport(respond)
+	gateway := NewGateway(first)
+	defer gateway.Close()
+
+	gateway.Reconnect = &ReconnectPolicy{InitialDelay: time.Millisecond, MaxAttempts: 1}
+	gateway.Dial = func() (Transport, error) {
+		return newMemTransport(respond), nil
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := gateway.Info()
+		errs <- err
+	}()
+
+	for gateway.transactions.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	first.Close()
+
+	select {
+	case err := <-errs:
+		if err != ErrConnectionLost {
+			t.Errorf("expected ErrConnectionLost, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pending request not failed after reconnecting")
+	}
+}
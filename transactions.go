@@ -0,0 +1,219 @@
+package janus
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// ErrTransactionExpired is returned by requests whose response did not
+// arrive within Gateway.TransactionTimeout.
+var ErrTransactionExpired = errors.New("janus: transaction expired")
+
+// ErrConnectionLost is returned by requests still waiting for a response
+// when the connection to the gateway was lost, as the response will never
+// arrive on the new connection.
+var ErrConnectionLost = errors.New("janus: connection lost")
+
+// ErrNoEvent is returned by PendingMessage.Event when the plugin answered
+// the message synchronously, without an event.
+var ErrNoEvent = errors.New("janus: message answered without an event")
+
+// DefaultTransactionTimeout is the TransactionTimeout of new Gateways.
+const DefaultTransactionTimeout = 5 * time.Minute
+
+type transactionState int
+
+const (
+	// transactionPending is waiting for a response.
+	transactionPending transactionState = iota
+	// transactionAcked received an ack, and is waiting for the plugin event.
+	transactionAcked
+	// transactionCompleted received its final response.
+	transactionCompleted
+)
+
+// transaction tracks a request sent to the gateway until the final
+// response to it arrives.
+type transaction struct {
+	id     xid.ID
+	method string
+	ch     chan interface{}
+	state  transactionState
+
+	// events receives the plugin event answering a message, while ch
+	// receives the ack or synchronous reply.
+	events  chan interface{}
+	evented bool
+
+	// replied is closed when a message is answered by anything else than an
+	// ack or an event, which is kept in reply, as no event will follow.
+	replied chan struct{}
+	reply   interface{}
+
+	// deadline is when the transaction is considered abandoned, if set.
+	deadline time.Time
+
+	// gen is the generation of the transport the request was sent on.
+	gen uint64
+
+	// expired is closed when the transaction is expired, or failed with err.
+	expired chan struct{}
+	err     error
+}
+
+// failure returns the error of an expired transaction.
+func (tx *transaction) failure() error {
+	if tx.err != nil {
+		return tx.err
+	}
+	return ErrTransactionExpired
+}
+
+// transactionManager keeps track of the in-flight transactions of a Gateway.
+type transactionManager struct {
+	sync.Mutex
+	transactions map[xid.ID]*transaction
+}
+
+func newTransactionManager() *transactionManager {
+	return &transactionManager{
+		transactions: make(map[xid.ID]*transaction),
+	}
+}
+
+func (tm *transactionManager) add(method string, ch chan interface{}, timeout time.Duration, gen uint64) *transaction {
+	tx := &transaction{
+		id:      xid.New(),
+		method:  method,
+		ch:      ch,
+		state:   transactionPending,
+		expired: make(chan struct{}),
+		gen:     gen,
+	}
+	if method == "message" {
+		tx.events = make(chan interface{}, 1)
+		tx.replied = make(chan struct{})
+	}
+	if timeout > 0 {
+		tx.deadline = time.Now().Add(timeout)
+	}
+
+	tm.Lock()
+	tm.transactions[tx.id] = tx
+	tm.Unlock()
+
+	return tx
+}
+
+func (tm *transactionManager) remove(id xid.ID) {
+	tm.Lock()
+	delete(tm.transactions, id)
+	tm.Unlock()
+}
+
+// resolve looks up the transaction a message was received for and advances
+// its state. Transactions are removed once their final response arrives,
+// which is the ack for most requests, but both the ack and the plugin event
+// for messages, which may arrive in any order over HTTP.
+// nil is returned for messages of unknown transactions.
+func (tm *transactionManager) resolve(id string, msg interface{}) *transaction {
+	guid, err := xid.FromString(id)
+	if err != nil {
+		return nil
+	}
+
+	tm.Lock()
+	defer tm.Unlock()
+
+	tx := tm.transactions[guid]
+	if tx == nil {
+		return nil
+	}
+
+	switch msg.(type) {
+	case *AckMsg:
+		if tx.method == "message" && !tx.evented {
+			tx.state = transactionAcked
+		} else {
+			tx.state = transactionCompleted
+		}
+	case *EventMsg:
+		tx.evented = true
+		if tx.method != "message" || tx.state == transactionAcked {
+			tx.state = transactionCompleted
+		}
+	default:
+		tx.state = transactionCompleted
+		if tx.replied != nil {
+			tx.reply = msg
+			close(tx.replied)
+		}
+	}
+
+	if tx.state == transactionCompleted {
+		delete(tm.transactions, guid)
+	}
+
+	return tx
+}
+
+// expire removes the transactions whose deadline passed, notifying their
+// requesters.
+func (tm *transactionManager) expire(now time.Time) {
+	tm.Lock()
+	defer tm.Unlock()
+
+	for id, tx := range tm.transactions {
+		if !tx.deadline.IsZero() && tx.deadline.Before(now) {
+			delete(tm.transactions, id)
+			close(tx.expired)
+		}
+	}
+}
+
+// failBefore removes the transactions sent on a transport older than the
+// generation gen, failing their requesters with err.
+func (tm *transactionManager) failBefore(gen uint64, err error) {
+	tm.Lock()
+	defer tm.Unlock()
+
+	for id, tx := range tm.transactions {
+		if tx.gen >= gen {
+			continue
+		}
+		delete(tm.transactions, id)
+		tx.err = err
+		close(tx.expired)
+	}
+}
+
+func (tm *transactionManager) count() int {
+	tm.Lock()
+	defer tm.Unlock()
+	return len(tm.transactions)
+}
+
+// InFlightTransactions returns the number of requests sent through the
+// Gateway which are still waiting for their final response.
+func (gateway *Gateway) InFlightTransactions() int {
+	return gateway.transactions.count()
+}
+
+// expireTransactions periodically expires the transactions which have been
+// waiting longer than Gateway.TransactionTimeout, until the Gateway is
+// closed.
+func (gateway *Gateway) expireTransactions() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			gateway.transactions.expire(now)
+		case <-gateway.closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package janus
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestGateway_TransactionLifecycle(t *testing.T) {
+	gateway := NewGateway(newMemTransport(func(req map[string]interface{}) []string {
+		tx := req["transaction"]
+		switch req["janus"] {
+		case "info":
+			return []string{fmt.Sprintf(`{"janus":"server_info","transaction":"%s"}`, tx)}
+		case "keepalive":
+			// never answered
+			return nil
+		}
+		t.Errorf("unexpected request %v", req["janus"])
+		return nil
+	}))
+	defer gateway.Close()
+
+	for i := 0; i < 10; i++ {
+		if _, err := gateway.Info(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := gateway.InFlightTransactions(); n != 0 {
+		t.Errorf("expected completed transactions to be removed, found %d", n)
+	}
+
+	gateway.TransactionTimeout = 20 * time.Millisecond
+	session := &Session{ID: 1, gateway: gateway}
+	if _, err := session.KeepAlive(); err != ErrTransactionExpired {
+		t.Errorf("expected %v, got %v", ErrTransactionExpired, err)
+	}
+	if n := gateway.InFlightTransactions(); n != 0 {
+		t.Errorf("expected expired transactions to be removed, found %d", n)
+	}
+}
+
+func TestTransactionManager_Resolve(t *testing.T) {
+	tm := newTransactionManager()
+	tx := tm.add("message", nil, 0, 0)
+
+	tm.resolve(tx.id.String(), &AckMsg{})
+	if tx.state != transactionAcked || tm.count() != 1 {
+		t.Errorf("expected acked message to stay in-flight")
+	}
+
+	tm.resolve(tx.id.String(), &EventMsg{})
+	if tx.state != transactionCompleted || tm.count() != 0 {
+		t.Errorf("expected message to be completed by the event")
+	}
+
+	if tm.resolve(tx.id.String(), &EventMsg{}) != nil {
+		t.Errorf("expected no transaction for a completed message")
+	}
+}
+
+func TestTransactionManager_FailBefore(t *testing.T) {
+	tm := newTransactionManager()
+	old := tm.add("info", nil, 0, 0)
+	current := tm.add("info", nil, 0, 1)
+
+	// only the requests sent on the lost transport fail
+	tm.failBefore(1, ErrConnectionLost)
+
+	select {
+	case <-old.expired:
+		if err := old.failure(); err != ErrConnectionLost {
+			t.Errorf("expected %v, got %v", ErrConnectionLost, err)
+		}
+	default:
+		t.Error("expected the transaction of the old transport to fail")
+	}
+	select {
+	case <-current.expired:
+		t.Error("the transaction of the new transport should not fail")
+	default:
+	}
+	if n := tm.count(); n != 1 {
+		t.Errorf("expected 1 transaction left, found %d", n)
+	}
+}
@@ -0,0 +1,70 @@
+package janus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Transport carries frames of the Janus API between a Gateway and an
+// instance of the Janus Gateway.
+type Transport interface {
+	// Send writes a single JSON encoded request.
+	Send(data []byte) error
+
+	// Receive blocks until the next JSON encoded message is received.
+	Receive() ([]byte, error)
+
+	// Ping checks that the connection is still alive.
+	Ping() error
+
+	Close() error
+}
+
+// ContextSender is implemented by transports whose Send waits for the
+// server, e.g. HttpTransport. The Gateway then sends requests through
+// SendContext, so that they are given up with the context of the request.
+type ContextSender interface {
+	SendContext(ctx context.Context, data []byte) error
+}
+
+// WebsocketTransport is a Transport using the websocket interface of the
+// Janus Gateway.
+type WebsocketTransport struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+// DialWebsocket initiates a websocket connection with the Janus Gateway.
+func DialWebsocket(wsURL string) (*WebsocketTransport, error) {
+	dialer := *websocket.DefaultDialer
+	dialer.Subprotocols = []string{"janus-protocol"}
+
+	conn, _, err := dialer.Dial(wsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebsocketTransport{conn: conn}, nil
+}
+
+func (t *WebsocketTransport) Send(data []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	return t.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (t *WebsocketTransport) Receive() ([]byte, error) {
+	_, data, err := t.conn.ReadMessage()
+	return data, err
+}
+
+func (t *WebsocketTransport) Ping() error {
+	return t.conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(20*time.Second))
+}
+
+func (t *WebsocketTransport) Close() error {
+	return t.conn.Close()
+}
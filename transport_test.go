@@ -0,0 +1,76 @@
+package janus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// memTransport is an in-memory Transport answering requests through a
+// callback.
+type memTransport struct {
+	in      chan []byte
+	closed  chan struct{}
+	once    sync.Once
+	respond func(req map[string]interface{}) []string
+}
+
+func newMemTransport(respond func(req map[string]interface{}) []string) *memTransport {
+	return &memTransport{
+		in:      make(chan []byte, 8),
+		closed:  make(chan struct{}),
+		respond: respond,
+	}
+}
+
+func (t *memTransport) Send(data []byte) error {
+	var req map[string]interface{}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return err
+	}
+	for _, resp := range t.respond(req) {
+		t.in <- []byte(resp)
+	}
+	return nil
+}
+
+func (t *memTransport) Receive() ([]byte, error) {
+	select {
+	case data := <-t.in:
+		return data, nil
+	case <-t.closed:
+		return nil, errors.New("closed")
+	}
+}
+
+func (t *memTransport) Ping() error {
+	return nil
+}
+
+func (t *memTransport) Close() error {
+	t.once.Do(func() { close(t.closed) })
+	return nil
+}
+
+func TestNewGateway(t *testing.T) {
+	transport := newMemTransport(func(req map[string]interface{}) []string {
+		if req["janus"] != "info" {
+			t.Errorf("unexpected request %v", req["janus"])
+			return nil
+		}
+		return []string{fmt.Sprintf(`{"janus":"server_info","transaction":"%s","name":"Janus WebRTC Server"}`, req["transaction"])}
+	})
+
+	gateway := NewGateway(transport)
+	defer gateway.Close()
+
+	info, err := gateway.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "Janus WebRTC Server" {
+		t.Errorf("unexpected name %s", info.Name)
+	}
+}
@@ -13,9 +13,11 @@
 // inspected to determine where the message should be delivered. Messages
 // with an ID field defined are considered responses to previous requests, and
 // will be passed directly to requester. Messages without an ID field are
-// considered unsolicited events from the gateway and are expected to have
-// both Session and Handle fields defined. They will be passed to the Events
-// channel of the related Handle and can be read from there.
+// considered unsolicited events from the gateway. Events with both Session
+// and Handle fields defined will be passed to the Events channel of the
+// related Handle, while events with only a Session field defined (e.g.
+// TimeoutMsg) will be passed to the Events channel of the related Session,
+// and can be read from there.
 
 package janus
 
@@ -72,7 +74,10 @@ type SuccessData struct {
 	ID uint64
 }
 
-type DetachedMsg struct{}
+type DetachedMsg struct {
+	Session uint64 `json:"session_id"`
+	Handle  uint64 `json:"sender"`
+}
 
 type InfoMsg struct {
 	Name          string
@@ -0,0 +1,107 @@
+package janus
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// Size of the buffer messages are read into. Datagrams larger than this are
+// truncated by the kernel, so it should be able to hold any message Janus
+// sends, SDPs included.
+const unixBufferSize = 1 << 18
+
+// UnixTransport is a Transport using the Unix Sockets interface of the Janus
+// Gateway (janus.transport.pfunix). Every message is sent as a single
+// SOCK_SEQPACKET or SOCK_DGRAM packet.
+type UnixTransport struct {
+	conn  *net.UnixConn
+	local string
+	buf   []byte
+}
+
+// DialUnix connects to the Unix Sockets interface of the Janus Gateway.
+// The URL takes the form unix:///path/to/sock, the socket type can be
+// chosen with a type query parameter set to either seqpacket (the default)
+// or dgram, e.g. unix:///path/to/sock?type=dgram.
+func DialUnix(unixURL string) (*UnixTransport, error) {
+	u, err := url.Parse(unixURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "unix" {
+		return nil, fmt.Errorf("unsupported scheme %s", u.Scheme)
+	}
+
+	path := u.Path
+	if path == "" {
+		path = u.Opaque
+	}
+
+	t := new(UnixTransport)
+	t.buf = make([]byte, unixBufferSize)
+
+	switch sockType := strings.TrimPrefix(strings.ToLower(u.Query().Get("type")), "sock_"); sockType {
+	case "", "seqpacket":
+		t.conn, err = net.DialUnix("unixpacket", nil, &net.UnixAddr{Name: path, Net: "unixpacket"})
+	case "dgram":
+		// Janus replies to the address of the sender, so a datagram socket
+		// needs to be bound to a path of its own.
+		t.local = filepath.Join(os.TempDir(), fmt.Sprintf("janus-go-%s.sock", xid.New()))
+		t.conn, err = net.DialUnix("unixgram",
+			&net.UnixAddr{Name: t.local, Net: "unixgram"},
+			&net.UnixAddr{Name: path, Net: "unixgram"})
+		if err != nil {
+			os.Remove(t.local)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported socket type %s", sockType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *UnixTransport) Send(data []byte) error {
+	_, err := t.conn.Write(data)
+	return err
+}
+
+func (t *UnixTransport) Receive() ([]byte, error) {
+	n, err := t.conn.Read(t.buf)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, n)
+	copy(data, t.buf[:n])
+	return data, nil
+}
+
+// SetDeadline sets the read and write deadline of the socket, see
+// net.Conn.SetDeadline. A zero value disables it.
+func (t *UnixTransport) SetDeadline(deadline time.Time) error {
+	return t.conn.SetDeadline(deadline)
+}
+
+// Ping is a no-op, as a broken local socket is reported by the next read or
+// write.
+func (t *UnixTransport) Ping() error {
+	return nil
+}
+
+func (t *UnixTransport) Close() error {
+	err := t.conn.Close()
+	if t.local != "" {
+		os.Remove(t.local)
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package janus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// serveUnix answers info requests on a local socket standing in for the
+// Unix Sockets interface of Janus.
+func serveUnix(t *testing.T, network, path string) {
+	answer := func(data []byte) []byte {
+		var req map[string]interface{}
+		if err := json.Unmarshal(data, &req); err != nil {
+			t.Error(err)
+		}
+		return []byte(fmt.Sprintf(`{"janus":"server_info","transaction":"%s","name":"%s"}`, req["transaction"], network))
+	}
+
+	buf := make([]byte, 4096)
+	switch network {
+	case "unixpacket":
+		l, err := net.Listen(network, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			defer l.Close()
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			for {
+				n, err := conn.Read(buf)
+				if err != nil {
+					return
+				}
+				conn.Write(answer(buf[:n]))
+			}
+		}()
+	case "unixgram":
+		conn, err := net.ListenPacket(network, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			defer conn.Close()
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			conn.WriteTo(answer(buf[:n]), addr)
+		}()
+	}
+}
+
+func TestConnect_Unix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "janus-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for network, query := range map[string]string{"unixpacket": "", "unixgram": "?type=SOCK_DGRAM"} {
+		path := filepath.Join(dir, network)
+		serveUnix(t, network, path)
+
+		client, err := Connect("unix://" + path + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := client.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Name != network {
+			t.Errorf("expected info from %s, got %s", network, info.Name)
+		}
+		client.Close()
+	}
+}